@@ -0,0 +1,52 @@
+package classifier
+
+import "github.com/dimuls/classifier/entity"
+
+// knownWordsRatio returns ratio of words known to classifier. N-grams are
+// skipped, since they are mostly unknown even if their words are known.
+// Should be called with classifier mutex locked.
+func (c *Classifier) knownWordsRatio(words []string) float64 {
+	known := c.knownWords()
+
+	var n, total int
+	for _, w := range words {
+		if isNGram(w) {
+			continue
+		}
+		total++
+		if _, isKnown := known[w]; isKnown {
+			n++
+		}
+	}
+
+	if total == 0 {
+		return 0
+	}
+
+	return float64(n) / float64(total)
+}
+
+// abstain makes classification result unknown if it isn't confident enough
+// according to configuration.
+func (c *Classifier) abstain(res *entity.Classification) {
+	c.configMutex.RLock()
+	defer c.configMutex.RUnlock()
+
+	if res.KnownWordsRatio < c.config.MinKnownWordsRatio {
+		res.Class = ""
+		res.Unknown = true
+		res.Reason = entity.FewKnownWords
+		return
+	}
+
+	minProbability, exists := c.config.ClassMinProbabilities[res.Class]
+	if !exists {
+		minProbability = c.config.MinProbability
+	}
+
+	if res.Probabilities[res.Class] < minProbability {
+		res.Class = ""
+		res.Unknown = true
+		res.Reason = entity.LowProbability
+	}
+}
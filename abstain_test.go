@@ -0,0 +1,116 @@
+package classifier
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dimuls/classifier/entity"
+)
+
+func TestAbstain(t *testing.T) {
+	tests := []struct {
+		name   string
+		config entity.ModelConfig
+		ratio  float64
+		reason entity.UnknownReason
+	}{
+		{
+			name:  "default config",
+			ratio: 0.1,
+		},
+		{
+			name:   "probability is enough",
+			config: entity.ModelConfig{MinProbability: 0.6},
+			ratio:  1,
+		},
+		{
+			name:   "low probability",
+			config: entity.ModelConfig{MinProbability: 0.7},
+			ratio:  1,
+			reason: entity.LowProbability,
+		},
+		{
+			name: "class probability is enough",
+			config: entity.ModelConfig{
+				MinProbability:        0.7,
+				ClassMinProbabilities: map[string]float64{"a": 0.5},
+			},
+			ratio: 1,
+		},
+		{
+			name: "low class probability",
+			config: entity.ModelConfig{
+				ClassMinProbabilities: map[string]float64{"a": 0.7},
+			},
+			ratio:  1,
+			reason: entity.LowProbability,
+		},
+		{
+			name:   "few known words",
+			config: entity.ModelConfig{MinKnownWordsRatio: 0.5},
+			ratio:  0.4,
+			reason: entity.FewKnownWords,
+		},
+	}
+
+	for _, test := range tests {
+		c := NewClassifier(fieldsWordsExtractor{})
+
+		err := c.SetConfig(test.config)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		res := entity.Classification{
+			Class:           "a",
+			Probabilities:   map[string]float64{"a": 0.6, "b": 0.4},
+			KnownWordsRatio: test.ratio,
+		}
+
+		c.abstain(&res)
+
+		unknown := test.reason != ""
+
+		if res.Unknown != unknown || res.Reason != test.reason {
+			t.Errorf("%s: got unknown %v with reason %q, want %v with "+
+				"reason %q", test.name, res.Unknown, res.Reason, unknown,
+				test.reason)
+		}
+		if unknown && res.Class != "" {
+			t.Errorf("%s: got class %q of unknown result", test.name,
+				res.Class)
+		}
+		if !unknown && res.Class != "a" {
+			t.Errorf("%s: got class %q, want \"a\"", test.name, res.Class)
+		}
+	}
+}
+
+func TestClassifyKnownWordsRatio(t *testing.T) {
+	c := newTrainedClassifier(t, testDocs)
+
+	err := c.SetConfig(entity.ModelConfig{MinKnownWordsRatio: 0.6})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := c.Classify(context.Background(), "football goal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.KnownWordsRatio != 1 || res.Class != "sport" {
+		t.Errorf("got class %q with known words ratio %v, want \"sport\" "+
+			"with ratio 1", res.Class, res.KnownWordsRatio)
+	}
+
+	res, err = c.Classify(context.Background(), "football tennis")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.KnownWordsRatio != 0.5 || !res.Unknown ||
+		res.Reason != entity.FewKnownWords {
+		t.Errorf("got known words ratio %v and reason %q, want ratio 0.5 "+
+			"and reason %q", res.KnownWordsRatio, res.Reason,
+			entity.FewKnownWords)
+	}
+}
@@ -0,0 +1,104 @@
+package classifier
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+
+	"github.com/jbrukh/bayesian"
+)
+
+// bayesianModel mirrors serialization format of bayesian classifier. It gives
+// access to class data which bayesian package doesn't expose.
+type bayesianModel struct {
+	Classes         []bayesian.Class
+	Learned         int
+	Seen            int
+	Datas           map[bayesian.Class]*classData
+	TfIdf           bool
+	DidConvertTfIdf bool
+}
+
+type classData struct {
+	Freqs   map[string]float64
+	FreqTfs map[string][]float64
+	Total   int
+}
+
+func newClassData() *classData {
+	return &classData{
+		Freqs:   map[string]float64{},
+		FreqTfs: map[string][]float64{},
+	}
+}
+
+func newBayesianModel(c *bayesian.Classifier) (*bayesianModel, error) {
+	buf := bytes.NewBuffer(nil)
+
+	err := c.WriteTo(buf)
+	if err != nil {
+		return nil, errors.New("failed to serialize classifier: " + err.Error())
+	}
+
+	var m bayesianModel
+
+	err = gob.NewDecoder(buf).Decode(&m)
+	if err != nil {
+		return nil, errors.New("failed to decode classifier: " + err.Error())
+	}
+
+	// bayesian classifier panics learning class with missing data, so it's
+	// restored to be safe.
+	if m.Datas == nil {
+		m.Datas = map[bayesian.Class]*classData{}
+	}
+	for _, class := range m.Classes {
+		d := m.Datas[class]
+		if d == nil {
+			d = newClassData()
+			m.Datas[class] = d
+		}
+		if d.Freqs == nil {
+			d.Freqs = map[string]float64{}
+		}
+		if d.FreqTfs == nil {
+			d.FreqTfs = map[string][]float64{}
+		}
+	}
+
+	return &m, nil
+}
+
+func (m *bayesianModel) classifier() (*bayesian.Classifier, error) {
+	buf := bytes.NewBuffer(nil)
+
+	err := gob.NewEncoder(buf).Encode(m)
+	if err != nil {
+		return nil, errors.New("failed to encode classifier: " + err.Error())
+	}
+
+	c, err := bayesian.NewClassifierFromReader(buf)
+	if err != nil {
+		return nil, errors.New("failed to deserialize classifier: " +
+			err.Error())
+	}
+
+	return c, nil
+}
+
+// addClasses returns copy of classifier with additional empty classes.
+func addClasses(c *bayesian.Classifier, classes []bayesian.Class) (
+	*bayesian.Classifier, error) {
+
+	m, err := newBayesianModel(c)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, class := range classes {
+		m.Classes = append(m.Classes, class)
+		m.Datas[class] = newClassData()
+	}
+
+	return m.classifier()
+}
@@ -0,0 +1,292 @@
+package classifier
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/dimuls/classifier/entity"
+)
+
+// ExtractionCache is a LRU cache of words extraction results. It's shared by
+// words extractors wrapped by it, results of different extractors are told
+// apart by their descriptions.
+type ExtractionCache struct {
+	maxSize int
+
+	entries map[string]*list.Element
+	order   *list.List
+	mutex   sync.Mutex
+
+	hits   int64
+	misses int64
+}
+
+// cacheEntry is a cached extraction result. Words are cached for words and
+// tokens extraction and phrases are cached for phrases extraction. Both are
+// cached for tokens and phrases extraction.
+type cacheEntry struct {
+	Key     string
+	Words   []string
+	Phrases [][]string
+}
+
+// NewExtractionCache creates cache which keeps at most maxSize last used
+// extraction results.
+func NewExtractionCache(maxSize int) (*ExtractionCache, error) {
+	if maxSize < 1 {
+		return nil, errors.New("cache size should be positive")
+	}
+
+	return &ExtractionCache{
+		maxSize: maxSize,
+		entries: map[string]*list.Element{},
+		order:   list.New(),
+	}, nil
+}
+
+func (c *ExtractionCache) get(key string) (cacheEntry, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	el, exists := c.entries[key]
+	if !exists {
+		atomic.AddInt64(&c.misses, 1)
+		return cacheEntry{}, false
+	}
+
+	atomic.AddInt64(&c.hits, 1)
+
+	c.order.MoveToFront(el)
+
+	return el.Value.(cacheEntry), true
+}
+
+func (c *ExtractionCache) put(e cacheEntry) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if el, exists := c.entries[e.Key]; exists {
+		el.Value = e
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.entries[e.Key] = c.order.PushFront(e)
+
+	for c.order.Len() > c.maxSize {
+		el := c.order.Back()
+		c.order.Remove(el)
+		delete(c.entries, el.Value.(cacheEntry).Key)
+	}
+}
+
+// Stats returns cache statistics.
+func (c *ExtractionCache) Stats() entity.CacheStats {
+	c.mutex.Lock()
+	size := c.order.Len()
+	c.mutex.Unlock()
+
+	return entity.CacheStats{
+		Size:    size,
+		MaxSize: c.maxSize,
+		Hits:    atomic.LoadInt64(&c.hits),
+		Misses:  atomic.LoadInt64(&c.misses),
+	}
+}
+
+// Save writes cached results to file.
+func (c *ExtractionCache) Save(path string) error {
+	c.mutex.Lock()
+	entries := make([]cacheEntry, 0, c.order.Len())
+	// The least recently used result goes first, so loading keeps order.
+	for el := c.order.Back(); el != nil; el = el.Prev() {
+		entries = append(entries, el.Value.(cacheEntry))
+	}
+	c.mutex.Unlock()
+
+	return writeFileAtomic(path, func(w io.Writer) error {
+		return gob.NewEncoder(w).Encode(entries)
+	})
+}
+
+// Load adds cached results from file written by Save.
+func (c *ExtractionCache) Load(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var entries []cacheEntry
+
+	err = gob.NewDecoder(f).Decode(&entries)
+	if err != nil {
+		return errors.New("failed to decode cache: " + err.Error())
+	}
+
+	for _, e := range entries {
+		c.put(e)
+	}
+
+	return nil
+}
+
+// Wrap returns words extractor which caches results of we. Words extractor
+// which can't describe itself is returned as is, since its results can't be
+// told apart from results of other words extractors.
+func (c *ExtractionCache) Wrap(we WordsExtractor) WordsExtractor {
+	info := describeWordsExtractor(we)
+	if info.Name == "" {
+		return we
+	}
+
+	return &CachingWordsExtractor{
+		wordsExtractor: we,
+		cache:          c,
+		keyPrefix:      describedKey(info),
+	}
+}
+
+// describedKey returns hash of words extractor description.
+func describedKey(info entity.WordsExtractorInfo) string {
+	settings := make([]string, 0, len(info.Settings))
+	for k, v := range info.Settings {
+		settings = append(settings, k+"="+v)
+	}
+	sort.Strings(settings)
+
+	hash := sha256.Sum256([]byte(info.Name + "\n" + info.StopWordsHash +
+		"\n" + strings.Join(settings, "\n")))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// CachingWordsExtractor is a words extractor which returns cached results
+// of wrapped words extractor.
+type CachingWordsExtractor struct {
+	wordsExtractor WordsExtractor
+	cache          *ExtractionCache
+	keyPrefix      string
+}
+
+// Kinds of extraction results.
+const (
+	wordsKind         = "words"
+	tokensKind        = "tokens"
+	phrasesKind       = "phrases"
+	tokensPhrasesKind = "tokens_phrases"
+)
+
+func (we *CachingWordsExtractor) key(kind string, text string) string {
+	hash := sha256.Sum256([]byte(text))
+	return we.keyPrefix + ":" + kind + ":" + hex.EncodeToString(hash[:])
+}
+
+// cachedWords returns cached words or extracts and caches them. Returned
+// words have no spare capacity, so appending to them doesn't change cache.
+func (we *CachingWordsExtractor) cachedWords(kind string, text string,
+	extract func() ([]string, error)) ([]string, error) {
+
+	key := we.key(kind, text)
+
+	if e, exists := we.cache.get(key); exists {
+		return e.Words, nil
+	}
+
+	words, err := extract()
+	if err != nil {
+		return nil, err
+	}
+
+	words = words[:len(words):len(words)]
+
+	we.cache.put(cacheEntry{Key: key, Words: words})
+
+	return words, nil
+}
+
+func (we *CachingWordsExtractor) ExtractWords(text string) ([]string,
+	error) {
+	return we.ExtractWordsContext(context.Background(), text)
+}
+
+func (we *CachingWordsExtractor) ExtractWordsContext(ctx context.Context,
+	text string) ([]string, error) {
+
+	return we.cachedWords(wordsKind, text, func() ([]string, error) {
+		return extractWordsContext(ctx, we.wordsExtractor, text)
+	})
+}
+
+func (we *CachingWordsExtractor) ExtractTokens(text string) ([]string,
+	error) {
+	return we.ExtractTokensContext(context.Background(), text)
+}
+
+func (we *CachingWordsExtractor) ExtractTokensContext(ctx context.Context,
+	text string) ([]string, error) {
+
+	return we.cachedWords(tokensKind, text, func() ([]string, error) {
+		return extractTokensContext(ctx, we.wordsExtractor, text)
+	})
+}
+
+func (we *CachingWordsExtractor) ExtractPhrases(text string) ([][]string,
+	error) {
+	return we.ExtractPhrasesContext(context.Background(), text)
+}
+
+func (we *CachingWordsExtractor) ExtractPhrasesContext(ctx context.Context,
+	text string) ([][]string, error) {
+
+	key := we.key(phrasesKind, text)
+
+	if e, exists := we.cache.get(key); exists {
+		return e.Phrases, nil
+	}
+
+	phrases, err := extractPhrasesContext(ctx, we.wordsExtractor, text)
+	if err != nil {
+		return nil, err
+	}
+
+	we.cache.put(cacheEntry{Key: key, Phrases: phrases})
+
+	return phrases, nil
+}
+
+func (we *CachingWordsExtractor) ExtractTokensPhrasesContext(
+	ctx context.Context, text string) ([]string, [][]string, error) {
+
+	key := we.key(tokensPhrasesKind, text)
+
+	if e, exists := we.cache.get(key); exists {
+		return e.Words, e.Phrases, nil
+	}
+
+	tokens, phrases, err := extractTokensPhrasesContext(ctx,
+		we.wordsExtractor, text)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tokens = tokens[:len(tokens):len(tokens)]
+
+	we.cache.put(cacheEntry{Key: key, Words: tokens, Phrases: phrases})
+
+	return tokens, phrases, nil
+}
+
+func (we *CachingWordsExtractor) Describe() entity.WordsExtractorInfo {
+	return describeWordsExtractor(we.wordsExtractor)
+}
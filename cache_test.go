@@ -0,0 +1,195 @@
+package classifier
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dimuls/classifier/entity"
+)
+
+// countingWordsExtractor splits text by spaces and counts extractions.
+type countingWordsExtractor struct {
+	name  string
+	calls int
+}
+
+func (we *countingWordsExtractor) ExtractWords(text string) ([]string,
+	error) {
+	we.calls++
+	return strings.Fields(text), nil
+}
+
+func (we *countingWordsExtractor) ExtractPhrases(text string) ([][]string,
+	error) {
+	we.calls++
+	return [][]string{strings.Fields(text)}, nil
+}
+
+func (we *countingWordsExtractor) Describe() entity.WordsExtractorInfo {
+	return entity.WordsExtractorInfo{Name: we.name}
+}
+
+func newTestCache(t *testing.T, size int) *ExtractionCache {
+	c, err := NewExtractionCache(size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func mustExtractWords(t *testing.T, we WordsExtractor,
+	text string) []string {
+
+	words, err := we.ExtractWords(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return words
+}
+
+func TestExtractionCacheHit(t *testing.T) {
+	c := newTestCache(t, 10)
+	we := &countingWordsExtractor{name: "counting"}
+	cwe := c.Wrap(we)
+
+	first := mustExtractWords(t, cwe, "a b")
+	second := mustExtractWords(t, cwe, "a b")
+
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("cached words %q differ from extracted %q", second, first)
+	}
+	if we.calls != 1 {
+		t.Errorf("words are extracted %d times, want 1", we.calls)
+	}
+
+	// Appending to returned words doesn't change cached ones.
+	_ = append(second, "c")
+	if words := mustExtractWords(t, cwe, "a b"); len(words) != 2 {
+		t.Errorf("cached words are changed to %q", words)
+	}
+
+	phrases, err := cwe.(PhrasesExtractor).ExtractPhrases("a b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(phrases) != 1 || we.calls != 2 {
+		t.Errorf("phrases %q are served from words cache", phrases)
+	}
+
+	stats := c.Stats()
+	want := entity.CacheStats{Size: 2, MaxSize: 10, Hits: 2, Misses: 2}
+	if stats != want {
+		t.Errorf("got stats %+v, want %+v", stats, want)
+	}
+}
+
+func TestExtractionCacheSeparatesExtractors(t *testing.T) {
+	c := newTestCache(t, 10)
+	a := &countingWordsExtractor{name: "a"}
+	b := &countingWordsExtractor{name: "b"}
+
+	mustExtractWords(t, c.Wrap(a), "text")
+	mustExtractWords(t, c.Wrap(b), "text")
+
+	if a.calls != 1 || b.calls != 1 {
+		t.Errorf("words extractors are called %d and %d times, want once",
+			a.calls, b.calls)
+	}
+
+	// Words extractor which can't describe itself isn't cached.
+	var we WordsExtractor = descriptionlessWordsExtractor{}
+	if c.Wrap(we) != we {
+		t.Error("words extractor without description is wrapped")
+	}
+}
+
+type descriptionlessWordsExtractor struct{}
+
+func (descriptionlessWordsExtractor) ExtractWords(text string) ([]string,
+	error) {
+	return strings.Fields(text), nil
+}
+
+func TestExtractionCacheEviction(t *testing.T) {
+	c := newTestCache(t, 2)
+	we := &countingWordsExtractor{name: "counting"}
+	cwe := c.Wrap(we)
+
+	mustExtractWords(t, cwe, "a")
+	mustExtractWords(t, cwe, "b")
+
+	// "a" is used, so "b" is the least recently used one.
+	mustExtractWords(t, cwe, "a")
+	mustExtractWords(t, cwe, "c")
+
+	if size := c.Stats().Size; size != 2 {
+		t.Errorf("got cache size %d, want 2", size)
+	}
+
+	calls := we.calls
+
+	mustExtractWords(t, cwe, "a")
+	if we.calls != calls {
+		t.Error("recently used result is evicted")
+	}
+
+	mustExtractWords(t, cwe, "b")
+	if we.calls != calls+1 {
+		t.Error("least recently used result isn't evicted")
+	}
+}
+
+func TestExtractionCacheSaveLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "cache")
+
+	c := newTestCache(t, 2)
+	cwe := c.Wrap(&countingWordsExtractor{name: "counting"})
+
+	mustExtractWords(t, cwe, "a")
+	mustExtractWords(t, cwe, "b")
+	mustExtractWords(t, cwe, "a")
+
+	err = c.Save(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := newTestCache(t, 2)
+
+	err = loaded.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	we := &countingWordsExtractor{name: "counting"}
+	lwe := loaded.Wrap(we)
+
+	// Loading keeps usage order, so "b" is evicted by "c".
+	mustExtractWords(t, lwe, "c")
+	mustExtractWords(t, lwe, "a")
+	if we.calls != 1 {
+		t.Errorf("words are extracted %d times, want 1", we.calls)
+	}
+
+	mustExtractWords(t, lwe, "b")
+	if we.calls != 2 {
+		t.Error("least recently used result isn't evicted after loading")
+	}
+}
+
+func TestNewExtractionCache(t *testing.T) {
+	_, err := NewExtractionCache(0)
+	if err == nil {
+		t.Error("cache of zero size is created")
+	}
+}
@@ -1,8 +1,14 @@
 package classifier
 
 import (
+	"context"
 	"errors"
+	"math"
+	"runtime"
+	"sort"
+	"strconv"
 	"sync"
+	"sync/atomic"
 
 	"github.com/jbrukh/bayesian"
 	"github.com/sirupsen/logrus"
@@ -14,25 +20,110 @@ type WordsExtractor interface {
 	ExtractWords(text string) ([]string, error)
 }
 
+// TokensExtractor is implemented by words extractors which can extract words
+// keeping their order and repetitions. Term frequencies of TF-IDF classifier
+// are counted using it.
+type TokensExtractor interface {
+	ExtractTokens(text string) ([]string, error)
+}
+
+// WordsExtractorDescriber is implemented by words extractors which can
+// describe their settings. Description is persisted with model to detect
+// models served with different words extractor than they are trained with.
+type WordsExtractorDescriber interface {
+	Describe() entity.WordsExtractorInfo
+}
+
+// describeWordsExtractor returns description of words extractor or empty
+// description if words extractor can't describe itself.
+func describeWordsExtractor(we WordsExtractor) entity.WordsExtractorInfo {
+	if d, ok := we.(WordsExtractorDescriber); ok {
+		return d.Describe()
+	}
+	return entity.WordsExtractorInfo{}
+}
+
 type Classifier struct {
-	wordsExtractor  WordsExtractor
+	newWordsExtractor WordsExtractorFactory
+
 	classifier      *bayesian.Classifier
 	classifierMutex sync.RWMutex
 
+	// extraction is a features extraction classifier is trained with and
+	// trainedWith is a description of its words extractor at training
+	// time. They are guarded by classifier mutex.
+	extraction  extraction
+	trainedWith entity.WordsExtractorInfo
+
+	// version and trainingVersion are guarded by classifier mutex.
+	version         int64
+	trainingVersion int64
+	lastVersion     int64
+
+	// knownWordsCache is built on demand and reset on every classifier
+	// change under classifier mutex.
+	knownWordsCache map[string]struct{}
+	knownWordsMutex sync.Mutex
+
+	config      entity.ModelConfig
+	configMutex sync.RWMutex
+
+	// workers is a number of goroutines extracting words of documents
+	// during training and batch classification.
+	workers int
+
 	log *logrus.Entry
 }
 
+// NewClassifier creates classifier which uses words extractor as is.
 func NewClassifier(we WordsExtractor) *Classifier {
+	c := NewConfigurableClassifier(StaticWordsExtractorFactory(we))
+	c.extraction.wordsExtractor = we
+	return c
+}
+
+// NewConfigurableClassifier creates classifier which creates words extractor
+// configured by config for every training.
+func NewConfigurableClassifier(f WordsExtractorFactory) *Classifier {
 	return &Classifier{
-		wordsExtractor: we,
-		log:            logrus.WithField("subsystem", "classifier"),
+		newWordsExtractor: f,
+		workers:           runtime.NumCPU(),
+		log:               logrus.WithField("subsystem", "classifier"),
+	}
+}
+
+// SetWorkers sets number of goroutines extracting words of documents. It's
+// a number of CPUs by default. It should be set before classifier is used.
+func (c *Classifier) SetWorkers(workers int) {
+	if workers < 1 {
+		workers = runtime.NumCPU()
 	}
+	c.workers = workers
 }
 
-func (c *Classifier) Train(docs []entity.Document) {
+// Train builds new classifier from documents. Previous classifier serves
+// classification until the new one is built and swapped in. Progress is
+// called with number of processed documents if it's not nil. Classifier is
+// trained with TF-IDF and word n-grams if they are configured. Words of
+// documents are extracted concurrently and training stops on the first
+// failed document.
+func (c *Classifier) Train(ctx context.Context, docs []entity.Document,
+	progress func(processed int)) error {
+
+	err := entity.ValidateDocuments(docs)
+	if err != nil {
+		return err
+	}
+
 	classesMap := map[string]struct{}{}
 	for _, d := range docs {
-		classesMap[d.Class] = struct{}{}
+		for _, class := range d.AllClasses() {
+			classesMap[class] = struct{}{}
+		}
+	}
+
+	if len(classesMap) < 2 {
+		return errors.New("at least two classes required to train")
 	}
 
 	var classes []bayesian.Class
@@ -40,73 +131,338 @@ func (c *Classifier) Train(docs []entity.Document) {
 		classes = append(classes, bayesian.Class(class))
 	}
 
-	classifier := bayesian.NewClassifier(classes...)
+	sort.Slice(classes, func(i, j int) bool {
+		return classes[i] < classes[j]
+	})
 
-	for _, d := range docs {
-		words, err := c.wordsExtractor.ExtractWords(d.Text)
-		if err != nil {
-			c.log.WithError(err).Error(
-				"failed to extract words from document text")
-			return
+	version := c.newVersion()
+
+	c.classifierMutex.Lock()
+	c.trainingVersion = version
+	c.classifierMutex.Unlock()
+
+	defer func() {
+		c.classifierMutex.Lock()
+		if c.trainingVersion == version {
+			c.trainingVersion = 0
 		}
-		classifier.Learn(words, bayesian.Class(d.Class))
+		c.classifierMutex.Unlock()
+	}()
+
+	e, err := c.newExtraction(c.Config())
+	if err != nil {
+		return err
+	}
+
+	var classifier *bayesian.Classifier
+	if e.counts {
+		classifier = bayesian.NewClassifierTfIdf(classes...)
+	} else {
+		classifier = bayesian.NewClassifier(classes...)
+	}
+
+	// Words are extracted concurrently, but learned in order of documents,
+	// so the same documents always result in the same classifier.
+	err = parallelOrdered(ctx, len(docs), c.workers,
+		func(ctx context.Context, i int) ([]string, error) {
+			words, err := c.extractWords(ctx, docs[i].Text, e)
+			if err != nil {
+				return nil, errors.New("failed to extract words from " +
+					"document " + strconv.Itoa(i) + " text: " + err.Error())
+			}
+			return words, nil
+		},
+		func(i int, words []string) error {
+			for _, class := range docs[i].AllClasses() {
+				classifier.Learn(words, bayesian.Class(class))
+			}
+			if progress != nil {
+				progress(i + 1)
+			}
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+
+	if e.counts {
+		classifier.ConvertTermsFreqToTfIdf()
 	}
 
 	c.classifierMutex.Lock()
 	c.classifier = classifier
+	c.extraction = e
+	c.trainedWith = describeWordsExtractor(e.wordsExtractor)
+	c.knownWordsCache = nil
+	c.version = version
 	c.classifierMutex.Unlock()
+
+	return nil
 }
 
-func (c *Classifier) Trained() bool {
+func (c *Classifier) newVersion() int64 {
+	return atomic.AddInt64(&c.lastVersion, 1)
+}
+
+// Training reports whether new classifier is being trained.
+func (c *Classifier) Training() bool {
 	c.classifierMutex.RLock()
 	defer c.classifierMutex.RUnlock()
 
-	return c.classifier != nil
+	return c.trainingVersion != 0
 }
 
-func (c *Classifier) Classify(text string) (string, error) {
+func (c *Classifier) TrainingStatus() entity.TrainingStatus {
 	c.classifierMutex.RLock()
-	if c.classifier == nil {
-		c.classifierMutex.RUnlock()
-		return "", errors.New("classifier is not trained")
-	}
-	c.classifierMutex.RUnlock()
+	defer c.classifierMutex.RUnlock()
 
-	words, err := c.wordsExtractor.ExtractWords(text)
-	if err != nil {
-		return "", errors.New("failed to extract words from text: " + err.Error())
+	return entity.TrainingStatus{
+		Training:        c.trainingVersion != 0,
+		LiveVersion:     c.version,
+		TrainingVersion: c.trainingVersion,
 	}
+}
 
-	if len(words) == 0 {
-		return "", errors.New("no words extracted from text")
+// Learn feeds documents to trained classifier without rebuilding it. Classes
+// unknown to classifier are added to it. Untrained classifier is trained
+// using documents. TF-IDF classifier can't learn, it should be retrained.
+func (c *Classifier) Learn(ctx context.Context, docs []entity.Document) error {
+	err := entity.ValidateDocuments(docs)
+	if err != nil {
+		return err
 	}
 
 	c.classifierMutex.RLock()
-	_, i, _ := c.classifier.LogScores(words)
+	e := c.extraction
+	trained := c.classifier != nil
 	c.classifierMutex.RUnlock()
 
-	return string(c.classifier.Classes[i]), nil
+	// Untrained classifier is trained with configured extraction.
+	if !trained {
+		var err error
+		e, err = c.newExtraction(c.Config())
+		if err != nil {
+			return err
+		}
+	}
+
+	docsWords := make([][]string, len(docs))
+
+	for i, d := range docs {
+		words, err := c.extractWords(ctx, d.Text, e)
+		if err != nil {
+			return errors.New(
+				"failed to extract words from document text: " + err.Error())
+		}
+		docsWords[i] = words
+	}
+
+	c.classifierMutex.Lock()
+	defer c.classifierMutex.Unlock()
+
+	if (c.classifier == nil && c.Config().TfIdf) ||
+		(c.classifier != nil && c.classifier.IsTfIdf()) {
+		return errors.New("TF-IDF classifier can't learn, it should be " +
+			"trained")
+	}
+
+	// Classifier can be learned or replaced while words are extracted.
+	// Learned words are still valid unless classifier is replaced by one
+	// with different extraction.
+	if c.classifier != nil && !sameExtraction(c.extraction, e) {
+		return errors.New("classifier is changed while learning")
+	}
+
+	var newClasses []bayesian.Class
+
+	newClassesMap := map[string]struct{}{}
+
+	for _, d := range docs {
+		for _, class := range d.AllClasses() {
+			if _, exists := newClassesMap[class]; exists {
+				continue
+			}
+			if c.classifier != nil && hasClass(c.classifier, class) {
+				continue
+			}
+			newClassesMap[class] = struct{}{}
+			newClasses = append(newClasses, bayesian.Class(class))
+		}
+	}
+
+	classifier := c.classifier
+
+	if classifier == nil {
+		if len(newClasses) < 2 {
+			return errors.New("at least two classes required to train")
+		}
+		classifier = bayesian.NewClassifier(newClasses...)
+	} else if len(newClasses) > 0 {
+		var err error
+		classifier, err = addClasses(classifier, newClasses)
+		if err != nil {
+			return errors.New("failed to add classes: " + err.Error())
+		}
+	}
+
+	for i, d := range docs {
+		for _, class := range d.AllClasses() {
+			classifier.Learn(docsWords[i], bayesian.Class(class))
+		}
+	}
+
+	if c.classifier == nil {
+		c.extraction = e
+		c.trainedWith = describeWordsExtractor(e.wordsExtractor)
+	}
+
+	c.classifier = classifier
+	c.knownWordsCache = nil
+	c.version = c.newVersion()
+
+	return nil
+}
+
+func hasClass(c *bayesian.Classifier, class string) bool {
+	for _, cl := range c.Classes {
+		if string(cl) == class {
+			return true
+		}
+	}
+	return false
 }
 
-func (c *Classifier) Save(path string) {
+func (c *Classifier) Trained() bool {
 	c.classifierMutex.RLock()
 	defer c.classifierMutex.RUnlock()
-	if c.classifier == nil {
-		return
+
+	return c.classifier != nil
+}
+
+// Classify classifies text. Classification fails with ErrExtractionTimeout
+// if words aren't extracted before ctx deadline.
+func (c *Classifier) Classify(ctx context.Context, text string) (
+	entity.Classification, error) {
+
+	if !c.Trained() {
+		return entity.Classification{}, errors.New("classifier is not trained")
 	}
-	err := c.classifier.WriteToFile(path)
+
+	words, err := c.extractWords(ctx, text, c.currentExtraction())
 	if err != nil {
-		c.log.WithError(err).Error("failed to save classifier to file")
+		return entity.Classification{}, errors.New(
+			"failed to extract words from text: " + err.Error())
 	}
+
+	return c.classifyWords(words)
 }
 
-func (c *Classifier) Load(path string) {
-	classifier, err := bayesian.NewClassifierFromFile(path)
+// ClassifyLabels classifies text in multi-label mode. Labels of result are
+// selected by options.
+func (c *Classifier) ClassifyLabels(ctx context.Context, text string,
+	o entity.LabelsOptions) (entity.Classification, error) {
+
+	res, err := c.Classify(ctx, text)
 	if err != nil {
-		c.log.WithError(err).Error("failed to load classifier from file")
-		return
+		return res, err
 	}
-	c.classifierMutex.Lock()
-	c.classifier = classifier
-	c.classifierMutex.Unlock()
+
+	res.Labels = res.SelectLabels(o)
+
+	return res, nil
+}
+
+// ClassifyBatch classifies texts extracting their words concurrently. Results
+// and errors are returned in the same order as texts. Error is not nil for
+// texts which failed to classify.
+func (c *Classifier) ClassifyBatch(ctx context.Context, texts []string) (
+	[]entity.Classification, []error) {
+
+	results := make([]entity.Classification, len(texts))
+	errs := make([]error, len(texts))
+
+	if !c.Trained() {
+		for i := range errs {
+			errs[i] = errors.New("classifier is not trained")
+		}
+		return results, errs
+	}
+
+	e := c.currentExtraction()
+
+	parallel(len(texts), c.workers, func(i int) {
+		words, err := c.extractWords(ctx, texts[i], e)
+		if err != nil {
+			errs[i] = errors.New(
+				"failed to extract words from text: " + err.Error())
+			return
+		}
+		results[i], errs[i] = c.classifyWords(words)
+	})
+
+	return results, errs
+}
+
+func (c *Classifier) classifyWords(words []string) (
+	entity.Classification, error) {
+
+	if len(words) == 0 {
+		return entity.Classification{}, errors.New("no words extracted from text")
+	}
+
+	c.classifierMutex.RLock()
+	scores, i, strict := c.classifier.LogScores(words)
+	classes := c.classifier.Classes
+	knownWordsRatio := c.knownWordsRatio(words)
+	c.classifierMutex.RUnlock()
+
+	res := newClassification(classes, scores, i, strict)
+	res.KnownWordsRatio = knownWordsRatio
+
+	c.abstain(&res)
+
+	return res, nil
+}
+
+// newClassification forms classification result from bayesian log scores.
+// Probabilities are computed with softmax over log scores, so they do not
+// underflow for long texts as bayesian ProbScores does.
+func newClassification(classes []bayesian.Class, scores []float64, i int,
+	strict bool) entity.Classification {
+
+	maxScore := scores[i]
+
+	// Every class has -Inf score if no class learned any of words, so
+	// classes are equally probable.
+	allInf := math.IsInf(maxScore, -1)
+
+	var sum float64
+	for _, s := range scores {
+		sum += math.Exp(s - maxScore)
+	}
+
+	res := entity.Classification{
+		Class:         string(classes[i]),
+		Probabilities: make(map[string]float64, len(classes)),
+		LogScores:     make(map[string]float64, len(classes)),
+		Strict:        strict,
+	}
+
+	for j, class := range classes {
+		if allInf {
+			res.Probabilities[string(class)] = 1 / float64(len(classes))
+		} else {
+			res.Probabilities[string(class)] =
+				math.Exp(scores[j]-maxScore) / sum
+		}
+		// Class without learned words has -Inf score, which can't be
+		// encoded to JSON.
+		if math.IsInf(scores[j], -1) {
+			res.LogScores[string(class)] = -math.MaxFloat64
+		} else {
+			res.LogScores[string(class)] = scores[j]
+		}
+	}
+
+	return res
 }
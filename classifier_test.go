@@ -0,0 +1,67 @@
+package classifier
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/jbrukh/bayesian"
+
+	"github.com/dimuls/classifier/entity"
+)
+
+func TestClassifyLabels(t *testing.T) {
+	c := newTrainedClassifier(t, []entity.Document{
+		{Classes: []string{"economics", "politics"},
+			Text: "budget tax vote"},
+		{Class: "politics", Text: "election vote party"},
+		{Class: "sport", Text: "football match goal"},
+	})
+
+	res, err := c.ClassifyLabels(context.Background(), "budget tax",
+		entity.LabelsOptions{MinProbability: 0.01})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Sport probability is below the minimal one.
+	if len(res.Labels) != 2 {
+		t.Fatalf("got labels %v, want economics and politics", res.Labels)
+	}
+	if res.Labels[0].Class != "economics" ||
+		res.Labels[1].Class != "politics" {
+		t.Errorf("got labels %v, want economics and politics", res.Labels)
+	}
+	if res.Class != "economics" {
+		t.Errorf("got class %q, want \"economics\"", res.Class)
+	}
+
+	res, err = c.ClassifyLabels(context.Background(), "budget tax",
+		entity.LabelsOptions{Top: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res.Labels) != 1 || res.Labels[0].Class != "economics" {
+		t.Errorf("got labels %v, want economics", res.Labels)
+	}
+}
+
+func TestNewClassificationInfScores(t *testing.T) {
+	classes := []bayesian.Class{"a", "b", "c", "d"}
+	scores := []float64{math.Inf(-1), math.Inf(-1), math.Inf(-1),
+		math.Inf(-1)}
+
+	res := newClassification(classes, scores, 0, false)
+
+	for _, class := range classes {
+		p := res.Probabilities[string(class)]
+		if p != 0.25 {
+			t.Errorf("got %q probability %v, want 0.25", class, p)
+		}
+		if s := res.LogScores[string(class)]; s != -math.MaxFloat64 {
+			t.Errorf("got %q log score %v, want %v", class, s,
+				-math.MaxFloat64)
+		}
+	}
+}
@@ -3,7 +3,6 @@ package main
 import (
 	"errors"
 	"fmt"
-	"html"
 	"net/http"
 	"strconv"
 	"strings"
@@ -14,6 +13,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/dimuls/classifier/entity"
+	"github.com/dimuls/classifier/normalizer"
 )
 
 const SourceName = "lenta.ru"
@@ -177,7 +177,7 @@ func (dl *DocumentsLoader) articleText(aURL string) (string, error) {
 
 	doc.Find(".b-text > p").Each(func(i int, s *goquery.Selection) {
 		ps = append(ps,
-			strings.TrimSpace(html.UnescapeString(s.Text())))
+			strings.TrimSpace(normalizer.UnescapeHTML(s.Text())))
 	})
 
 	return strings.Join(ps, " "), nil
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/pflag"
+
+	"github.com/dimuls/classifier/entity"
+)
+
+// runEvaluate cross-validates classifier configuration on documents of
+// docs file and logs evaluation results.
+func runEvaluate(args []string) {
+	var (
+		classifierURI string
+		docsFilePath  string
+		folds         int
+		seed          int64
+	)
+
+	flags := pflag.NewFlagSet("evaluate", pflag.ExitOnError)
+
+	flags.StringVar(&classifierURI, "classifier-uri",
+		"http://localhost:80", "classifier base URI")
+
+	flags.StringVar(&docsFilePath, "docs-file", "documents.json",
+		"documents file path")
+
+	flags.IntVar(&folds, "folds", 5, "cross-validation folds count")
+
+	flags.Int64Var(&seed, "seed", 0, "documents shuffle seed")
+
+	flags.Parse(args)
+
+	f, err := os.Open(docsFilePath)
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to open docs file")
+	}
+
+	var docs []entity.Document
+
+	err = json.NewDecoder(f).Decode(&docs)
+	f.Close()
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to decode docs from file")
+	}
+
+	reqJSON, err := json.Marshal(struct {
+		Documents []entity.Document
+		entity.EvaluationOptions
+	}{
+		Documents: docs,
+		EvaluationOptions: entity.EvaluationOptions{
+			Folds: folds,
+			Seed:  seed,
+		},
+	})
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to JSON marshal request")
+	}
+
+	res, err := http.Post(classifierURI+"/evaluate", "application/json",
+		bytes.NewReader(reqJSON))
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to post evaluate")
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		logrus.WithField("status_code", res.StatusCode).
+			Fatal("not OK status code")
+	}
+
+	var e entity.Evaluation
+
+	err = json.NewDecoder(res.Body).Decode(&e)
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to decode response body")
+	}
+
+	for _, m := range e.Classes {
+		logrus.WithFields(logrus.Fields{
+			"class":     m.Class,
+			"support":   m.Support,
+			"precision": m.Precision,
+			"recall":    m.Recall,
+			"f1":        m.F1,
+		}).Info("class evaluation")
+	}
+
+	for i, label := range e.Labels {
+		fields := logrus.Fields{"actual_class": label}
+		for k, predicted := range e.Labels {
+			fields[predicted] = e.ConfusionMatrix[i][k]
+		}
+		logrus.WithFields(fields).Info("confusion matrix row")
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"total_docs":      e.Documents,
+		"folds":           e.Folds,
+		"fold_accuracies": e.FoldAccuracies,
+		"accuracy":        e.Accuracy,
+		"unknown":         e.Unknown,
+	}).Info("total evaluation")
+}
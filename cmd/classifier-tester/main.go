@@ -14,6 +14,11 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "evaluate" {
+		runEvaluate(os.Args[2:])
+		return
+	}
+
 	var (
 		classifierURI string
 		reload        bool
@@ -150,50 +155,38 @@ func logStats(docs []entity.Document) {
 	}
 }
 
+// testBatchSize is a number of documents sent in one classify batch request.
+const testBatchSize = 100
+
 func testUsingDocs(classifierURI string, docs []entity.Document) {
 	totalErrors := 0
 
 	total := map[string]int{}
 	errors := map[string]int{}
 
-	classifyURI := classifierURI + "/classify"
-
-	type document struct {
-		Text string
-	}
-
-	for _, d := range docs {
-		dJSON, err := json.Marshal(document{Text: d.Text})
-		if err != nil {
-			logrus.WithError(err).
-				Fatal("failed to JSON marshal test document")
+	for from := 0; from < len(docs); from += testBatchSize {
+		to := from + testBatchSize
+		if to > len(docs) {
+			to = len(docs)
 		}
 
-		res, err := http.Post(classifyURI, "application/json",
-			bytes.NewReader(dJSON))
-		if err != nil {
-			logrus.WithError(err).Fatal("failed to post classify")
-		}
-
-		defer res.Body.Close()
-
-		if res.StatusCode != http.StatusOK {
-			logrus.WithField("status_code", res.StatusCode).
-				Fatal("not OK status code")
-		}
+		batch := docs[from:to]
 
-		var predictedClass string
+		results := classifyBatch(classifierURI, batch)
 
-		err = json.NewDecoder(res.Body).Decode(&predictedClass)
-		if err != nil {
-			logrus.WithError(err).Error("failed to decode response body")
-		}
+		for i, d := range batch {
+			total[d.Class]++
 
-		total[d.Class]++
+			if results[i].Error != "" {
+				logrus.WithField("error", results[i].Error).
+					Error("failed to classify document")
+			}
 
-		if d.Class != predictedClass {
-			errors[d.Class]++
-			totalErrors++
+			if results[i].Classification == nil ||
+				d.Class != results[i].Classification.Class {
+				errors[d.Class]++
+				totalErrors++
+			}
 		}
 	}
 
@@ -217,3 +210,56 @@ func testUsingDocs(classifierURI string, docs []entity.Document) {
 		"total_fail_rate": float64(totalErrors) / float64(len(docs)) * 100,
 	}).Info("total stats")
 }
+
+type batchResult struct {
+	Classification *entity.Classification
+	Error          string
+}
+
+func classifyBatch(classifierURI string,
+	docs []entity.Document) []batchResult {
+
+	type document struct {
+		Text string
+	}
+
+	batch := make([]document, len(docs))
+	for i, d := range docs {
+		batch[i].Text = d.Text
+	}
+
+	batchJSON, err := json.Marshal(batch)
+	if err != nil {
+		logrus.WithError(err).
+			Fatal("failed to JSON marshal test documents batch")
+	}
+
+	res, err := http.Post(classifierURI+"/classify/batch",
+		"application/json", bytes.NewReader(batchJSON))
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to post classify batch")
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		logrus.WithField("status_code", res.StatusCode).
+			Fatal("not OK status code")
+	}
+
+	var results []batchResult
+
+	err = json.NewDecoder(res.Body).Decode(&results)
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to decode response body")
+	}
+
+	if len(results) != len(docs) {
+		logrus.WithFields(logrus.Fields{
+			"docs":    len(docs),
+			"results": len(results),
+		}).Fatal("unexpected number of batch results")
+	}
+
+	return results
+}
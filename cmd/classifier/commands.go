@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+
+	"github.com/dimuls/classifier"
+	"github.com/dimuls/classifier/entity"
+)
+
+const commandsUsage = `usage:
+  classifier                                 run classifier service
+  classifier export <model-file> [json-file] export model to JSON
+  classifier import <json-file> <model-file> import model from JSON`
+
+// runCommand runs command given in arguments.
+func runCommand(args []string) error {
+	switch args[0] {
+	case "export":
+		if len(args) < 2 || len(args) > 3 {
+			return errors.New(commandsUsage)
+		}
+		var outPath string
+		if len(args) == 3 {
+			outPath = args[2]
+		}
+		return exportModel(args[1], outPath)
+	case "import":
+		if len(args) != 3 {
+			return errors.New(commandsUsage)
+		}
+		return importModel(args[1], args[2])
+	default:
+		return errors.New(commandsUsage)
+	}
+}
+
+// exportModel exports model file to JSON file or to stdout if outPath is
+// empty.
+func exportModel(modelPath string, outPath string) error {
+	e, err := classifier.ExportFile(modelPath)
+	if err != nil {
+		return errors.New("failed to export model: " + err.Error())
+	}
+
+	var out io.Writer = os.Stdout
+
+	if outPath != "" {
+		f, err := os.Create(outPath)
+		if err != nil {
+			return errors.New("failed to create JSON file: " + err.Error())
+		}
+		defer f.Close()
+		out = f
+	}
+
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+
+	err = enc.Encode(e)
+	if err != nil {
+		return errors.New("failed to write JSON: " + err.Error())
+	}
+
+	return nil
+}
+
+func importModel(jsonPath string, modelPath string) error {
+	f, err := os.Open(jsonPath)
+	if err != nil {
+		return errors.New("failed to open JSON file: " + err.Error())
+	}
+
+	defer f.Close()
+
+	var e entity.ModelExport
+
+	err = json.NewDecoder(f).Decode(&e)
+	if err != nil {
+		return errors.New("failed to decode JSON: " + err.Error())
+	}
+
+	err = classifier.ImportFile(e, modelPath)
+	if err != nil {
+		return errors.New("failed to import model: " + err.Error())
+	}
+
+	return nil
+}
@@ -1,30 +1,125 @@
 package main
 
 import (
+	"errors"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/sirupsen/logrus"
 
 	"github.com/dimuls/classifier"
+	"github.com/dimuls/classifier/entity"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		err := runCommand(os.Args[1:])
+		if err != nil {
+			logrus.WithError(err).Fatal("failed to run command")
+		}
+		return
+	}
+
+	var mystemPoolSize int
+
+	if s := os.Getenv("MYSTEM_POOL_SIZE"); s != "" {
+		var err error
+		mystemPoolSize, err = strconv.Atoi(s)
+		if err != nil {
+			logrus.WithError(err).Fatal("failed to parse mystem pool size")
+		}
+	}
+
+	var extractionWorkers int
+
+	if s := os.Getenv("EXTRACTION_WORKERS"); s != "" {
+		var err error
+		extractionWorkers, err = strconv.Atoi(s)
+		if err != nil {
+			logrus.WithError(err).Fatal("failed to parse extraction workers")
+		}
+	}
+
+	var snapshotsCount int
+
+	if s := os.Getenv("SNAPSHOTS_COUNT"); s != "" {
+		var err error
+		snapshotsCount, err = strconv.Atoi(s)
+		if err != nil {
+			logrus.WithError(err).Fatal("failed to parse snapshots count")
+		}
+	}
+
+	var autosaveInterval time.Duration
+
+	if s := os.Getenv("AUTOSAVE_INTERVAL"); s != "" {
+		var err error
+		autosaveInterval, err = time.ParseDuration(s)
+		if err != nil {
+			logrus.WithError(err).Fatal("failed to parse autosave interval")
+		}
+	}
+
+	var stopWordsFiles []string
 
-	service, err := classifier.NewService(
-		os.Getenv("MYSTEM_BIN_PATH"),
-		os.Getenv("CLASSIFIER_FILE_PATH"),
-		os.Getenv("WEB_SERVER_BIND_ADDR"),
-		os.Getenv("WEB_SERVER_DEBUG") == "1")
+	if s := os.Getenv("STOP_WORDS_FILES"); s != "" {
+		stopWordsFiles = strings.Split(s, ",")
+	}
+
+	var extractionCacheSize int
+
+	if s := os.Getenv("EXTRACTION_CACHE_SIZE"); s != "" {
+		var err error
+		extractionCacheSize, err = strconv.Atoi(s)
+		if err != nil {
+			logrus.WithError(err).Fatal("failed to parse extraction cache size")
+		}
+	}
+
+	var requestTimeout time.Duration
+
+	if s := os.Getenv("REQUEST_TIMEOUT"); s != "" {
+		var err error
+		requestTimeout, err = time.ParseDuration(s)
+		if err != nil {
+			logrus.WithError(err).Fatal("failed to parse request timeout")
+		}
+	}
+
+	modelConfig, err := parseModelConfig()
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to parse model config")
+	}
+
+	service, err := classifier.NewService(classifier.Config{
+		WordsExtractor:     os.Getenv("WORDS_EXTRACTOR"),
+		MystemBinPath:      os.Getenv("MYSTEM_BIN_PATH"),
+		MystemPoolSize:     mystemPoolSize,
+		ExtractionWorkers:  extractionWorkers,
+		StopWordsFiles:     stopWordsFiles,
+		DataDir:            os.Getenv("CLASSIFIER_DATA_DIR"),
+		ClassifierFilePath: os.Getenv("CLASSIFIER_FILE_PATH"),
+		SnapshotsCount:     snapshotsCount,
+		ModelConfig:        modelConfig,
+		AutosaveInterval:   autosaveInterval,
+		WebServerBindAddr:  os.Getenv("WEB_SERVER_BIND_ADDR"),
+		WebServerDebug:     os.Getenv("WEB_SERVER_DEBUG") == "1",
+		RequestTimeout:     requestTimeout,
+
+		ExtractionCacheSize:     extractionCacheSize,
+		ExtractionCacheFilePath: os.Getenv("EXTRACTION_CACHE_FILE_PATH"),
+	})
 	if err != nil {
 		logrus.WithError(err).Fatal("failed to create classifier service")
 	}
 
 	service.Start()
 
-	signals := make(chan os.Signal)
+	signals := make(chan os.Signal, 1)
 	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
 
 	signal := <-signals
@@ -40,3 +135,124 @@ func main() {
 	logrus.Infof("stopped in %g seconds, exiting",
 		endTime.Sub(startTime).Seconds())
 }
+
+// parseModelConfig parses default model config from environment.
+// CLASS_MIN_PROBABILITIES is a comma separated list of class:probability
+// pairs.
+func parseModelConfig() (entity.ModelConfig, error) {
+	var cfg entity.ModelConfig
+
+	if s := os.Getenv("MIN_PROBABILITY"); s != "" {
+		p, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return cfg, errors.New("failed to parse min probability: " +
+				err.Error())
+		}
+		cfg.MinProbability = p
+	}
+
+	if s := os.Getenv("CLASS_MIN_PROBABILITIES"); s != "" {
+		cfg.ClassMinProbabilities = map[string]float64{}
+
+		for _, pair := range strings.Split(s, ",") {
+			i := strings.LastIndex(pair, ":")
+			if i < 0 {
+				return cfg, errors.New("invalid class min probability " +
+					pair)
+			}
+			p, err := strconv.ParseFloat(pair[i+1:], 64)
+			if err != nil {
+				return cfg, errors.New("failed to parse class min " +
+					"probability: " + err.Error())
+			}
+			cfg.ClassMinProbabilities[strings.TrimSpace(pair[:i])] = p
+		}
+	}
+
+	if s := os.Getenv("MIN_KNOWN_WORDS_RATIO"); s != "" {
+		r, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return cfg, errors.New("failed to parse min known words ratio: " +
+				err.Error())
+		}
+		cfg.MinKnownWordsRatio = r
+	}
+
+	cfg.TfIdf = os.Getenv("TF_IDF") == "1"
+
+	if s := os.Getenv("NGRAMS"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return cfg, errors.New("failed to parse n-grams: " + err.Error())
+		}
+		cfg.NGrams = n
+	}
+
+	if s := os.Getenv("PARTS_OF_SPEECH"); s != "" {
+		for _, pos := range strings.Split(s, ",") {
+			cfg.Extraction.PartsOfSpeech = append(
+				cfg.Extraction.PartsOfSpeech, strings.TrimSpace(pos))
+		}
+	}
+
+	cfg.Extraction.DropProperNames = os.Getenv("DROP_PROPER_NAMES") == "1"
+	cfg.Extraction.DropNumerals = os.Getenv("DROP_NUMERALS") == "1"
+
+	n, err := parseNormalizationOptions()
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Extraction.Normalization = n
+
+	return cfg, nil
+}
+
+// parseNormalizationOptions parses text normalization options from
+// environment. NORMALIZATION is a comma separated list of steps: html,
+// unicode, urls, emails, dates, numbers and yo.
+func parseNormalizationOptions() (entity.NormalizationOptions, error) {
+	var o entity.NormalizationOptions
+
+	if s := os.Getenv("NORMALIZATION"); s != "" {
+		for _, step := range strings.Split(s, ",") {
+			switch strings.TrimSpace(step) {
+			case "html":
+				o.StripHTML = true
+			case "unicode":
+				o.NormalizeUnicode = true
+			case "urls":
+				o.ReplaceURLs = true
+			case "emails":
+				o.ReplaceEmails = true
+			case "dates":
+				o.ReplaceDates = true
+			case "numbers":
+				o.ReplaceNumbers = true
+			case "yo":
+				o.FoldYo = true
+			default:
+				return o, errors.New("unknown normalization step " + step)
+			}
+		}
+	}
+
+	if s := os.Getenv("MAX_WORD_LENGTH"); s != "" {
+		l, err := strconv.Atoi(s)
+		if err != nil {
+			return o, errors.New("failed to parse max word length: " +
+				err.Error())
+		}
+		o.MaxWordLength = l
+	}
+
+	if s := os.Getenv("MAX_TEXT_LENGTH"); s != "" {
+		l, err := strconv.Atoi(s)
+		if err != nil {
+			return o, errors.New("failed to parse max text length: " +
+				err.Error())
+		}
+		o.MaxTextLength = l
+	}
+
+	return o, nil
+}
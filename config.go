@@ -0,0 +1,101 @@
+package classifier
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/dimuls/classifier/entity"
+)
+
+// Config returns classification configuration of classifier.
+func (c *Classifier) Config() entity.ModelConfig {
+	c.configMutex.RLock()
+	defer c.configMutex.RUnlock()
+
+	return copyConfig(c.config)
+}
+
+// SetConfig validates and sets classification configuration of classifier.
+func (c *Classifier) SetConfig(cfg entity.ModelConfig) error {
+	err := validateConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	c.configMutex.Lock()
+	c.config = normalizeConfig(cfg)
+	c.configMutex.Unlock()
+
+	return nil
+}
+
+func copyConfig(cfg entity.ModelConfig) entity.ModelConfig {
+	if cfg.ClassMinProbabilities != nil {
+		ps := make(map[string]float64, len(cfg.ClassMinProbabilities))
+		for class, p := range cfg.ClassMinProbabilities {
+			ps[class] = p
+		}
+		cfg.ClassMinProbabilities = ps
+	}
+	cfg.Extraction.PartsOfSpeech = append([]string(nil),
+		cfg.Extraction.PartsOfSpeech...)
+	cfg.Extraction.StopWords = append([]entity.StopWord(nil),
+		cfg.Extraction.StopWords...)
+	return cfg
+}
+
+// normalizeConfig returns copy of config with lower case stop words, since
+// extracted words are in lower case.
+func normalizeConfig(cfg entity.ModelConfig) entity.ModelConfig {
+	cfg = copyConfig(cfg)
+	for i, sw := range cfg.Extraction.StopWords {
+		cfg.Extraction.StopWords[i].Word = strings.ToLower(sw.Word)
+	}
+	return cfg
+}
+
+func validateConfig(cfg entity.ModelConfig) error {
+	if cfg.MinProbability < 0 || cfg.MinProbability > 1 {
+		return errors.New("min probability should be in [0, 1]")
+	}
+
+	for class, p := range cfg.ClassMinProbabilities {
+		if p < 0 || p > 1 {
+			return errors.New("min probability of class " + class +
+				" should be in [0, 1]")
+		}
+	}
+
+	if cfg.MinKnownWordsRatio < 0 || cfg.MinKnownWordsRatio > 1 {
+		return errors.New("min known words ratio should be in [0, 1]")
+	}
+
+	if cfg.NGrams < 0 || cfg.NGrams > maxNGrams {
+		return errors.New("n-grams should be in [0, " +
+			strconv.Itoa(maxNGrams) + "]")
+	}
+
+	switch cfg.Extraction.StopWordsMode {
+	case "", entity.StopWordsMerge, entity.StopWordsReplace:
+	default:
+		return errors.New("unknown stop words mode " +
+			string(cfg.Extraction.StopWordsMode))
+	}
+
+	if cfg.Extraction.Normalization.MaxWordLength < 0 {
+		return errors.New("max word length should be non-negative")
+	}
+
+	if cfg.Extraction.Normalization.MaxTextLength < 0 {
+		return errors.New("max text length should be non-negative")
+	}
+
+	for _, sw := range cfg.Extraction.StopWords {
+		if sw.Word == "" || strings.ContainsAny(sw.Word, " \t\n") {
+			return errors.New("invalid stop word \"" + sw.Word + "\"")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,196 @@
+package classifier
+
+import (
+	"context"
+	"errors"
+)
+
+// ContextWordsExtractor is implemented by words extractors which stop
+// extraction when context is done.
+type ContextWordsExtractor interface {
+	ExtractWordsContext(ctx context.Context, text string) ([]string, error)
+}
+
+// ContextTokensExtractor is a TokensExtractor which stops extraction when
+// context is done.
+type ContextTokensExtractor interface {
+	ExtractTokensContext(ctx context.Context, text string) ([]string, error)
+}
+
+// ContextPhrasesExtractor is a PhrasesExtractor which stops extraction when
+// context is done.
+type ContextPhrasesExtractor interface {
+	ExtractPhrasesContext(ctx context.Context, text string) ([][]string,
+		error)
+}
+
+var (
+	ErrExtractionTimeout  = errors.New("words extraction timed out")
+	ErrExtractionCanceled = errors.New("words extraction is canceled")
+)
+
+// ContextAdapter adapts words extractor which doesn't support context.
+// Extraction isn't stopped when context is done, but it isn't waited for.
+type ContextAdapter struct {
+	WordsExtractor
+}
+
+func (a ContextAdapter) ExtractWordsContext(ctx context.Context,
+	text string) ([]string, error) {
+
+	return waitWords(ctx, func() ([]string, error) {
+		return a.ExtractWords(text)
+	})
+}
+
+// ExtractTokensContext extracts tokens if adapted words extractor is a
+// TokensExtractor and words otherwise.
+func (a ContextAdapter) ExtractTokensContext(ctx context.Context,
+	text string) ([]string, error) {
+
+	te, ok := a.WordsExtractor.(TokensExtractor)
+	if !ok {
+		return a.ExtractWordsContext(ctx, text)
+	}
+
+	return waitWords(ctx, func() ([]string, error) {
+		return te.ExtractTokens(text)
+	})
+}
+
+func (a ContextAdapter) ExtractPhrasesContext(ctx context.Context,
+	text string) ([][]string, error) {
+
+	pe, ok := a.WordsExtractor.(PhrasesExtractor)
+	if !ok {
+		return nil, errors.New("words extractor doesn't support n-grams")
+	}
+
+	type result struct {
+		phrases [][]string
+		err     error
+	}
+
+	results := make(chan result, 1)
+
+	go func() {
+		phrases, err := pe.ExtractPhrases(text)
+		results <- result{phrases: phrases, err: err}
+	}()
+
+	select {
+	case r := <-results:
+		return r.phrases, r.err
+	case <-ctx.Done():
+		return nil, contextError(ctx, ctx.Err())
+	}
+}
+
+// waitWords runs extract and waits for its result until context is done.
+func waitWords(ctx context.Context, extract func() ([]string, error)) (
+	[]string, error) {
+
+	type result struct {
+		words []string
+		err   error
+	}
+
+	results := make(chan result, 1)
+
+	go func() {
+		words, err := extract()
+		results <- result{words: words, err: err}
+	}()
+
+	select {
+	case r := <-results:
+		return r.words, r.err
+	case <-ctx.Done():
+		return nil, contextError(ctx, ctx.Err())
+	}
+}
+
+// contextError replaces extraction error by ErrExtractionTimeout or
+// ErrExtractionCanceled if it's caused by done context.
+func contextError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
+		return ErrExtractionTimeout
+	case context.Canceled:
+		return ErrExtractionCanceled
+	default:
+		return err
+	}
+}
+
+// extractsTokens reports whether words extractor can extract tokens.
+func extractsTokens(we WordsExtractor) bool {
+	_, ok := we.(TokensExtractor)
+	if !ok {
+		_, ok = we.(ContextTokensExtractor)
+	}
+	return ok
+}
+
+func extractWordsContext(ctx context.Context, we WordsExtractor,
+	text string) ([]string, error) {
+
+	if cwe, ok := we.(ContextWordsExtractor); ok {
+		words, err := cwe.ExtractWordsContext(ctx, text)
+		return words, contextError(ctx, err)
+	}
+
+	return ContextAdapter{we}.ExtractWordsContext(ctx, text)
+}
+
+func extractTokensContext(ctx context.Context, we WordsExtractor,
+	text string) ([]string, error) {
+
+	if cte, ok := we.(ContextTokensExtractor); ok {
+		tokens, err := cte.ExtractTokensContext(ctx, text)
+		return tokens, contextError(ctx, err)
+	}
+
+	return ContextAdapter{we}.ExtractTokensContext(ctx, text)
+}
+
+func extractPhrasesContext(ctx context.Context, we WordsExtractor,
+	text string) ([][]string, error) {
+
+	if cpe, ok := we.(ContextPhrasesExtractor); ok {
+		phrases, err := cpe.ExtractPhrasesContext(ctx, text)
+		return phrases, contextError(ctx, err)
+	}
+
+	return ContextAdapter{we}.ExtractPhrasesContext(ctx, text)
+}
+
+// extractTokensPhrasesContext extracts tokens and phrases at once if words
+// extractor supports it and one by one otherwise.
+func extractTokensPhrasesContext(ctx context.Context, we WordsExtractor,
+	text string) ([]string, [][]string, error) {
+
+	if tpe, ok := we.(ContextTokensPhrasesExtractor); ok {
+		tokens, phrases, err := tpe.ExtractTokensPhrasesContext(ctx, text)
+		if err != nil {
+			return nil, nil, contextError(ctx, err)
+		}
+		return tokens, phrases, nil
+	}
+
+	tokens, err := extractTokensContext(ctx, we, text)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	phrases, err := extractPhrasesContext(ctx, we, text)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tokens, phrases, nil
+}
@@ -0,0 +1,11 @@
+package entity
+
+// CacheStats are statistics of words extraction cache.
+type CacheStats struct {
+	// Size is a number of cached extraction results. MaxSize is its limit.
+	Size    int
+	MaxSize int
+
+	Hits   int64
+	Misses int64
+}
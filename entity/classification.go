@@ -0,0 +1,88 @@
+package entity
+
+import "sort"
+
+// UnknownReason is a reason of classification result to be unknown.
+type UnknownReason string
+
+const (
+	// LowProbability means that the most probable class probability is
+	// below the minimal probability.
+	LowProbability UnknownReason = "low_probability"
+
+	// FewKnownWords means that ratio of text words known to model is below
+	// the minimal ratio.
+	FewKnownWords UnknownReason = "few_known_words"
+)
+
+// Classification is a result of text classification.
+type Classification struct {
+	// Class is the most probable class. It's empty if result is unknown.
+	Class string
+
+	// Unknown is true if classification confidence is too low to choose
+	// any class. Reason describes why.
+	Unknown bool
+	Reason  UnknownReason
+
+	// KnownWordsRatio is a ratio of text words known to model.
+	KnownWordsRatio float64
+
+	// Probabilities are normalized probabilities of every known class.
+	Probabilities map[string]float64
+
+	// LogScores are raw log scores of every known class.
+	LogScores map[string]float64
+
+	// Strict is false when some other class has the same score as Class.
+	Strict bool
+
+	// Labels are the most probable classes selected by LabelsOptions in
+	// multi-label mode. They are ordered by descending probability.
+	Labels []Label
+}
+
+// Label is a class of multi-label classification.
+type Label struct {
+	Class       string
+	Probability float64
+}
+
+// LabelsOptions select labels of multi-label classification.
+type LabelsOptions struct {
+	// Top is a maximal number of labels. Zero means no limit.
+	Top int
+
+	// MinProbability is a minimal probability of label class.
+	MinProbability float64
+}
+
+// Enabled reports whether options enable multi-label mode.
+func (o LabelsOptions) Enabled() bool {
+	return o.Top > 0 || o.MinProbability > 0
+}
+
+// SelectLabels returns the most probable classes selected by options.
+func (c Classification) SelectLabels(o LabelsOptions) []Label {
+	labels := make([]Label, 0, len(c.Probabilities))
+
+	for class, p := range c.Probabilities {
+		if p < o.MinProbability {
+			continue
+		}
+		labels = append(labels, Label{Class: class, Probability: p})
+	}
+
+	sort.Slice(labels, func(i, j int) bool {
+		if labels[i].Probability != labels[j].Probability {
+			return labels[i].Probability > labels[j].Probability
+		}
+		return labels[i].Class < labels[j].Class
+	})
+
+	if o.Top > 0 && len(labels) > o.Top {
+		labels = labels[:o.Top]
+	}
+
+	return labels
+}
@@ -0,0 +1,64 @@
+package entity
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSelectLabels(t *testing.T) {
+	c := Classification{
+		Probabilities: map[string]float64{
+			"a": 0.1,
+			"b": 0.4,
+			"c": 0.1,
+			"d": 0.4,
+		},
+	}
+
+	tests := []struct {
+		options LabelsOptions
+		labels  []Label
+	}{
+		{
+			options: LabelsOptions{},
+			labels: []Label{{"b", 0.4}, {"d", 0.4}, {"a", 0.1},
+				{"c", 0.1}},
+		},
+		{
+			options: LabelsOptions{Top: 3},
+			labels:  []Label{{"b", 0.4}, {"d", 0.4}, {"a", 0.1}},
+		},
+		{
+			options: LabelsOptions{MinProbability: 0.2},
+			labels:  []Label{{"b", 0.4}, {"d", 0.4}},
+		},
+		{
+			options: LabelsOptions{Top: 1, MinProbability: 0.1},
+			labels:  []Label{{"b", 0.4}},
+		},
+		{
+			options: LabelsOptions{MinProbability: 0.5},
+			labels:  []Label{},
+		},
+	}
+
+	for _, test := range tests {
+		labels := c.SelectLabels(test.options)
+		if !reflect.DeepEqual(labels, test.labels) {
+			t.Errorf("SelectLabels(%+v) = %v, want %v", test.options,
+				labels, test.labels)
+		}
+	}
+}
+
+func TestLabelsOptionsEnabled(t *testing.T) {
+	if (LabelsOptions{}).Enabled() {
+		t.Error("default labels options enable multi-label mode")
+	}
+	if !(LabelsOptions{Top: 2}).Enabled() {
+		t.Error("top doesn't enable multi-label mode")
+	}
+	if !(LabelsOptions{MinProbability: 0.3}).Enabled() {
+		t.Error("min probability doesn't enable multi-label mode")
+	}
+}
@@ -0,0 +1,29 @@
+package entity
+
+// ModelConfig is a configuration of model classification.
+type ModelConfig struct {
+	// MinProbability is a minimal probability of the most probable class.
+	// Classification with lower probability results in unknown class.
+	MinProbability float64
+
+	// ClassMinProbabilities override MinProbability for some classes.
+	ClassMinProbabilities map[string]float64
+
+	// MinKnownWordsRatio is a minimal ratio of text words known to model.
+	// Classification of text with lower ratio results in unknown class.
+	MinKnownWordsRatio float64
+
+	// TfIdf enables training with TF-IDF word weights instead of word
+	// counts. TF-IDF model counts repeated words and can't learn
+	// incrementally. It's applied on the next training.
+	TfIdf bool
+
+	// NGrams is a maximal length of word n-grams used as features in
+	// addition to single words. N-grams don't span stop words. Zero and one
+	// mean single words only. It's applied on the next training.
+	NGrams int
+
+	// Extraction configures words extraction. It's applied on the next
+	// training.
+	Extraction ExtractionOptions
+}
@@ -1,6 +1,50 @@
 package entity
 
+import (
+	"errors"
+	"strconv"
+)
+
 type Document struct {
 	Text  string
 	Class string
+
+	// Classes are document classes for documents which belong to several
+	// classes. Class is used if they are empty.
+	Classes []string
+}
+
+// AllClasses returns unique document classes including Class.
+func (d Document) AllClasses() []string {
+	if len(d.Classes) == 0 {
+		return []string{d.Class}
+	}
+
+	classes := make([]string, 0, len(d.Classes)+1)
+	seen := make(map[string]struct{}, len(d.Classes)+1)
+
+	for _, class := range append([]string{d.Class}, d.Classes...) {
+		if class == "" {
+			continue
+		}
+		if _, exists := seen[class]; exists {
+			continue
+		}
+		seen[class] = struct{}{}
+		classes = append(classes, class)
+	}
+
+	return classes
+}
+
+// ValidateDocuments checks that every document has a non-empty class.
+func ValidateDocuments(docs []Document) error {
+	for i, d := range docs {
+		classes := d.AllClasses()
+		if len(classes) == 0 || classes[0] == "" {
+			return errors.New("document " + strconv.Itoa(i) +
+				" has no class")
+		}
+	}
+	return nil
 }
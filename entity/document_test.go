@@ -0,0 +1,54 @@
+package entity
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDocumentAllClasses(t *testing.T) {
+	tests := []struct {
+		doc     Document
+		classes []string
+	}{
+		{
+			doc:     Document{Class: "a"},
+			classes: []string{"a"},
+		},
+		{
+			doc:     Document{Classes: []string{"b", "a"}},
+			classes: []string{"b", "a"},
+		},
+		{
+			doc:     Document{Class: "a", Classes: []string{"b", "a", ""}},
+			classes: []string{"a", "b"},
+		},
+	}
+
+	for _, test := range tests {
+		classes := test.doc.AllClasses()
+		if !reflect.DeepEqual(classes, test.classes) {
+			t.Errorf("AllClasses() of %+v = %q, want %q", test.doc,
+				classes, test.classes)
+		}
+	}
+}
+
+func TestValidateDocuments(t *testing.T) {
+	err := ValidateDocuments([]Document{
+		{Class: "a"},
+		{Classes: []string{"b"}},
+	})
+	if err != nil {
+		t.Errorf("valid documents are rejected: %v", err)
+	}
+
+	for _, doc := range []Document{
+		{Text: "text"},
+		{Text: "text", Classes: []string{""}},
+	} {
+		err := ValidateDocuments([]Document{{Class: "a"}, doc})
+		if err == nil {
+			t.Errorf("document %+v without class is accepted", doc)
+		}
+	}
+}
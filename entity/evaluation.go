@@ -0,0 +1,77 @@
+package entity
+
+import (
+	"errors"
+	"strconv"
+)
+
+// DefaultFolds is a number of cross-validation folds if it isn't specified.
+const DefaultFolds = 5
+
+// EvaluationOptions configure cross-validation.
+type EvaluationOptions struct {
+	// Folds is a number of folds. Default is DefaultFolds.
+	Folds int
+
+	// Seed seeds shuffling of documents before they are split to folds.
+	// The same seed results in the same folds.
+	Seed int64
+}
+
+// ValidateEvaluationOptions checks that documents number of documents can be
+// split to o.Folds folds. Zero folds mean DefaultFolds.
+func ValidateEvaluationOptions(o EvaluationOptions, documents int) error {
+	folds := o.Folds
+	if folds == 0 {
+		folds = DefaultFolds
+	}
+
+	if folds < 2 {
+		return errors.New("at least two folds required")
+	}
+
+	if folds > documents {
+		return errors.New("number of folds " + strconv.Itoa(folds) +
+			" exceeds number of documents " + strconv.Itoa(documents))
+	}
+
+	return nil
+}
+
+// ClassMetrics are evaluation metrics of class.
+type ClassMetrics struct {
+	Class string
+
+	Precision float64
+	Recall    float64
+	F1        float64
+
+	// Support is a number of evaluated documents of class.
+	Support int
+}
+
+// Evaluation is a result of classifier evaluation. Multi-label documents
+// are evaluated by their first class.
+type Evaluation struct {
+	Documents int
+
+	// Folds is a number of cross-validation folds. It's zero if classifier
+	// is evaluated without cross-validation.
+	Folds int
+
+	// FoldAccuracies are accuracies of every fold.
+	FoldAccuracies []float64
+
+	Accuracy float64
+
+	// Unknown is a number of documents classified as unknown. They are
+	// counted as misclassified.
+	Unknown int
+
+	Classes []ClassMetrics
+
+	// ConfusionMatrix[i][j] is a number of documents of class Labels[i]
+	// classified as Labels[j].
+	Labels          []string
+	ConfusionMatrix [][]int
+}
@@ -0,0 +1,26 @@
+package entity
+
+import "testing"
+
+func TestValidateEvaluationOptions(t *testing.T) {
+	tests := []struct {
+		options   EvaluationOptions
+		documents int
+		valid     bool
+	}{
+		{options: EvaluationOptions{Folds: 2}, documents: 2, valid: true},
+		{options: EvaluationOptions{}, documents: DefaultFolds, valid: true},
+		{options: EvaluationOptions{}, documents: DefaultFolds - 1},
+		{options: EvaluationOptions{Folds: 1}, documents: 10},
+		{options: EvaluationOptions{Folds: -2}, documents: 10},
+		{options: EvaluationOptions{Folds: 3}, documents: 2},
+	}
+
+	for _, test := range tests {
+		err := ValidateEvaluationOptions(test.options, test.documents)
+		if (err == nil) != test.valid {
+			t.Errorf("ValidateEvaluationOptions(%+v, %d) = %v",
+				test.options, test.documents, err)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package entity
+
+// Explanation shows which words drove classification of text.
+type Explanation struct {
+	Classification Classification
+
+	// Classes are explanations for every known class.
+	Classes []ClassExplanation
+
+	// UnknownWords are extracted words which classifier doesn't know.
+	UnknownWords []string
+}
+
+// ClassExplanation holds the most contributing words of class ordered by
+// their log probabilities descending.
+type ClassExplanation struct {
+	Class string
+	Words []WordContribution
+}
+
+// WordContribution is a log probability of word in class.
+type WordContribution struct {
+	Word           string
+	LogProbability float64
+}
@@ -0,0 +1,75 @@
+package entity
+
+import "time"
+
+// ModelExportFormatVersion is a version of ModelExport format. It's
+// incremented on incompatible format changes.
+const ModelExportFormatVersion = 1
+
+// WordsExtractorInfo describes words extractor model is trained with. Model
+// should be served with the same words extractor.
+type WordsExtractorInfo struct {
+	// Name is a kind of words extractor, e.g. "mystem" or "stemmer".
+	Name string
+
+	// Settings are extractor specific settings which affect extracted words.
+	Settings map[string]string
+
+	// StopWordsHash is a hex encoded SHA-256 of sorted stop words list,
+	// joined by new lines.
+	StopWordsHash string
+}
+
+// ModelExport is a portable JSON representation of trained model which
+// doesn't depend on classifier implementation.
+//
+// Naive Bayes classifier is fully described by per class word counts.
+// Probability of word in class is its count divided by class Total.
+// Class prior probability is its Total divided by sum of all Totals.
+type ModelExport struct {
+	// FormatVersion is a ModelExportFormatVersion export is made with.
+	FormatVersion int
+
+	Created time.Time
+
+	WordsExtractor WordsExtractorInfo
+
+	// Documents is a number of documents model is trained with.
+	Documents int
+
+	// SeenDocuments is a number of documents classified by model.
+	SeenDocuments int
+
+	// TfIdf is true if model uses TF-IDF word weights instead of counts.
+	TfIdf bool
+
+	// TfIdfConverted is true if term frequencies of TF-IDF model are
+	// already converted to word weights and model can't learn anymore.
+	TfIdfConverted bool
+
+	// NGrams is a maximal length of word n-grams model is trained with.
+	// Words of n-gram are joined by space.
+	NGrams int
+
+	// Extraction is a words extraction configuration model is trained
+	// with.
+	Extraction ExtractionOptions
+
+	// Classes are in classifier order.
+	Classes []ClassExport
+}
+
+// ClassExport is a class data of ModelExport.
+type ClassExport struct {
+	Class string
+
+	// Total is a sum of class word counts.
+	Total int
+
+	// Words maps word to its count (or weight for TF-IDF model) in class.
+	Words map[string]float64
+
+	// TermFrequencies maps word to its frequencies in every learned
+	// document. It's used by TF-IDF models only.
+	TermFrequencies map[string][]float64
+}
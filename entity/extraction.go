@@ -0,0 +1,114 @@
+package entity
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// StopWord is a word which is skipped during words extraction.
+type StopWord struct {
+	Word string
+
+	// Language is an optional language tag of word, e.g. "ru" or "en".
+	Language string
+}
+
+// StopWordsMode is a way model stop words are combined with global ones.
+type StopWordsMode string
+
+const (
+	// StopWordsMerge adds model stop words to global ones. It's default.
+	StopWordsMerge StopWordsMode = "merge"
+
+	// StopWordsReplace uses model stop words instead of global ones.
+	StopWordsReplace StopWordsMode = "replace"
+)
+
+// ExtractionOptions configure words extraction of model.
+type ExtractionOptions struct {
+	// PartsOfSpeech are mystem part of speech tags of words to keep, e.g.
+	// "S" (noun), "V" (verb) or "A" (adjective). Empty keeps all words.
+	PartsOfSpeech []string
+
+	// DropProperNames drops names, surnames, patronymics and geographical
+	// names.
+	DropProperNames bool
+
+	// DropNumerals drops numerals and numbers.
+	DropNumerals bool
+
+	// StopWords are model stop words combined with global ones according
+	// to StopWordsMode.
+	StopWords     []StopWord
+	StopWordsMode StopWordsMode
+
+	// Normalization configures text normalization before words
+	// extraction.
+	Normalization NormalizationOptions
+}
+
+// IsDefault reports whether options don't change words extraction.
+func (o ExtractionOptions) IsDefault() bool {
+	return len(o.PartsOfSpeech) == 0 && !o.DropProperNames &&
+		!o.DropNumerals && len(o.StopWords) == 0 &&
+		(o.StopWordsMode == "" || o.StopWordsMode == StopWordsMerge) &&
+		o.Normalization.IsDefault()
+}
+
+// ParseStopWords parses stop words list. List is a plain text with one
+// word per line. Word can be prefixed by language tag and colon, e.g.
+// "ru:и" or "en:the". Empty lines and lines starting with # are skipped.
+func ParseStopWords(r io.Reader) ([]StopWord, error) {
+	var sws []StopWord
+
+	scanner := bufio.NewScanner(r)
+
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var sw StopWord
+
+		if i := strings.Index(line, ":"); i >= 0 {
+			sw.Language = strings.TrimSpace(line[:i])
+			line = strings.TrimSpace(line[i+1:])
+		}
+
+		if line == "" || strings.ContainsAny(line, " \t") {
+			return nil, errors.New("invalid stop word on line " +
+				strconv.Itoa(n))
+		}
+
+		sw.Word = strings.ToLower(line)
+
+		sws = append(sws, sw)
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return sws, nil
+}
+
+// WriteStopWords writes stop words list in format read by ParseStopWords.
+func WriteStopWords(w io.Writer, sws []StopWord) error {
+	bw := bufio.NewWriter(w)
+
+	for _, sw := range sws {
+		if sw.Language != "" {
+			bw.WriteString(sw.Language)
+			bw.WriteByte(':')
+		}
+		bw.WriteString(sw.Word)
+		bw.WriteByte('\n')
+	}
+
+	return bw.Flush()
+}
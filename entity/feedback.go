@@ -0,0 +1,11 @@
+package entity
+
+import "time"
+
+// Feedback is a report about classification of text.
+type Feedback struct {
+	Text      string
+	Predicted string
+	Correct   string
+	Time      time.Time
+}
@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+// ModelMetadata describes persisted model file.
+type ModelMetadata struct {
+	// Checksum is a hex encoded SHA-256 of model file.
+	Checksum string
+
+	Created   time.Time
+	Classes   []string
+	Documents int
+
+	// TfIdf is true if model is trained with TF-IDF.
+	TfIdf bool
+
+	// NGrams is a maximal length of word n-grams model is trained with.
+	NGrams int
+
+	// Extraction is a words extraction configuration model is trained
+	// with.
+	Extraction ExtractionOptions
+
+	WordsExtractor WordsExtractorInfo
+}
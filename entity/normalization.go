@@ -0,0 +1,37 @@
+package entity
+
+// NormalizationOptions configure text normalization before words
+// extraction. Steps are applied in order of fields.
+type NormalizationOptions struct {
+	// StripHTML removes HTML tags, scripts and styles and unescapes HTML
+	// entities.
+	StripHTML bool
+
+	// NormalizeUnicode applies NFKC normalization and removes invisible
+	// format characters like soft hyphens and zero width spaces.
+	NormalizeUnicode bool
+
+	// ReplaceURLs, ReplaceEmails, ReplaceDates and ReplaceNumbers replace
+	// URLs, e-mails, dates and numbers by placeholder words, so they are
+	// counted as one feature each.
+	ReplaceURLs    bool
+	ReplaceEmails  bool
+	ReplaceDates   bool
+	ReplaceNumbers bool
+
+	// FoldYo replaces "ё" by "е".
+	FoldYo bool
+
+	// MaxWordLength is a maximal length of words in runes. Longer words
+	// are removed. Zero means no limit.
+	MaxWordLength int
+
+	// MaxTextLength is a maximal length of normalized text in runes.
+	// Longer text is truncated. Zero means no limit.
+	MaxTextLength int
+}
+
+// IsDefault reports whether options don't change text.
+func (o NormalizationOptions) IsDefault() bool {
+	return o == NormalizationOptions{}
+}
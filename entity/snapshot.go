@@ -0,0 +1,18 @@
+package entity
+
+import "time"
+
+// Snapshot is a saved version of model.
+type Snapshot struct {
+	ID      string
+	Created time.Time
+
+	// TrainingJobID is an ID of job which trained snapshot model.
+	TrainingJobID string
+
+	Metadata ModelMetadata
+
+	// Evaluation is a result of the last snapshot model evaluation. It's nil
+	// if snapshot isn't evaluated.
+	Evaluation *Evaluation
+}
@@ -0,0 +1,71 @@
+package entity
+
+import "time"
+
+// TrainingStatus describes model versions. Version is changed on every
+// training, learning or loading of model. Zero version means no model.
+type TrainingStatus struct {
+	Training bool
+
+	// LiveVersion is a version of model which serves classification.
+	LiveVersion int64
+
+	// TrainingVersion is a version of model being trained.
+	TrainingVersion int64
+}
+
+type TrainingJobState string
+
+const (
+	TrainingJobQueued    TrainingJobState = "queued"
+	TrainingJobRunning   TrainingJobState = "running"
+	TrainingJobSucceeded TrainingJobState = "succeeded"
+	TrainingJobFailed    TrainingJobState = "failed"
+	TrainingJobCancelled TrainingJobState = "cancelled"
+)
+
+// TrainingJob is a state of model training.
+type TrainingJob struct {
+	ID    string
+	State TrainingJobState
+
+	// Processed is a number of processed documents out of Total.
+	Processed int
+	Total     int
+
+	Created  time.Time
+	Started  time.Time
+	Finished time.Time
+
+	// ElapsedSeconds is a running time of job.
+	ElapsedSeconds float64
+
+	// DocumentsPerSecond is an average number of documents processed per
+	// second of running time.
+	DocumentsPerSecond float64
+
+	Error string
+}
+
+// Done reports whether job is finished.
+func (j TrainingJob) Done() bool {
+	return j.State == TrainingJobSucceeded || j.State == TrainingJobFailed ||
+		j.State == TrainingJobCancelled
+}
+
+// ModelStatus describes state of model.
+type ModelStatus struct {
+	Name    string
+	Trained bool
+
+	TrainingStatus
+
+	// SavedVersion is a version of model saved to file at LastSaved time.
+	SavedVersion int64
+	LastSaved    time.Time
+
+	// WordsExtractorMismatch is true if model is served with words
+	// extractor which differs from one model is trained with, e.g. when
+	// stop words are changed. Model should be retrained.
+	WordsExtractorMismatch bool
+}
@@ -0,0 +1,274 @@
+package classifier
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sort"
+	"strconv"
+
+	"github.com/dimuls/classifier/entity"
+)
+
+// Evaluate classifies documents and compares results with their classes.
+func (c *Classifier) Evaluate(ctx context.Context, docs []entity.Document) (
+	entity.Evaluation, error) {
+
+	if len(docs) == 0 {
+		return entity.Evaluation{}, errors.New("no documents to evaluate")
+	}
+
+	err := entity.ValidateDocuments(docs)
+	if err != nil {
+		return entity.Evaluation{}, err
+	}
+
+	var p predictions
+
+	err = p.add(ctx, c, docs)
+	if err != nil {
+		return entity.Evaluation{}, err
+	}
+
+	return p.evaluation(), nil
+}
+
+// CrossValidate evaluates classifier configuration with stratified k-fold
+// cross-validation. Every fold is classified by in-memory classifier trained
+// on other folds, classifier itself isn't changed.
+func (c *Classifier) CrossValidate(ctx context.Context,
+	docs []entity.Document, o entity.EvaluationOptions) (entity.Evaluation,
+	error) {
+
+	err := entity.ValidateEvaluationOptions(o, len(docs))
+	if err != nil {
+		return entity.Evaluation{}, err
+	}
+
+	if o.Folds == 0 {
+		o.Folds = entity.DefaultFolds
+	}
+
+	err = entity.ValidateDocuments(docs)
+	if err != nil {
+		return entity.Evaluation{}, err
+	}
+
+	folds := stratifiedFolds(docs, o.Folds, o.Seed)
+
+	var (
+		p              predictions
+		foldAccuracies []float64
+	)
+
+	for i := range folds {
+		var train, test []entity.Document
+
+		for k, fold := range folds {
+			if k == i {
+				test = fold
+			} else {
+				train = append(train, fold...)
+			}
+		}
+
+		fc := NewConfigurableClassifier(c.newWordsExtractor)
+		fc.config = c.Config()
+		fc.workers = c.workers
+
+		err := fc.Train(ctx, train, nil)
+		if err != nil {
+			return entity.Evaluation{}, errors.New("failed to train fold " +
+				strconv.Itoa(i) + ": " + err.Error())
+		}
+
+		var fp predictions
+
+		err = fp.add(ctx, fc, test)
+		if err != nil {
+			return entity.Evaluation{}, errors.New("failed to evaluate fold " +
+				strconv.Itoa(i) + ": " + err.Error())
+		}
+
+		foldAccuracies = append(foldAccuracies, fp.accuracy())
+
+		p.actual = append(p.actual, fp.actual...)
+		p.predicted = append(p.predicted, fp.predicted...)
+	}
+
+	e := p.evaluation()
+	e.Folds = o.Folds
+	e.FoldAccuracies = foldAccuracies
+
+	return e, nil
+}
+
+// evaluatedClass returns class document is evaluated by. Document should be
+// validated.
+func evaluatedClass(d entity.Document) string {
+	return d.AllClasses()[0]
+}
+
+// stratifiedFolds splits documents to folds keeping class proportions.
+// Documents of every class are shuffled by seed and dealt to folds one by
+// one. Dealing continues from fold where previous class ended, so folds
+// sizes differ by one document at most.
+func stratifiedFolds(docs []entity.Document, folds int,
+	seed int64) [][]entity.Document {
+
+	byClass := map[string][]entity.Document{}
+	for _, d := range docs {
+		class := evaluatedClass(d)
+		byClass[class] = append(byClass[class], d)
+	}
+
+	classes := make([]string, 0, len(byClass))
+	for class := range byClass {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+
+	r := rand.New(rand.NewSource(seed))
+
+	result := make([][]entity.Document, folds)
+
+	next := 0
+
+	for _, class := range classes {
+		classDocs := byClass[class]
+
+		r.Shuffle(len(classDocs), func(i, j int) {
+			classDocs[i], classDocs[j] = classDocs[j], classDocs[i]
+		})
+
+		for _, d := range classDocs {
+			result[next] = append(result[next], d)
+			next = (next + 1) % folds
+		}
+	}
+
+	return result
+}
+
+// predictions are actual and predicted classes of documents. Predicted
+// class is empty if document is classified as unknown.
+type predictions struct {
+	actual    []string
+	predicted []string
+}
+
+// add classifies documents by c. Documents which fail to classify are
+// counted as unknown unless ctx is done.
+func (p *predictions) add(ctx context.Context, c *Classifier,
+	docs []entity.Document) error {
+
+	texts := make([]string, len(docs))
+	for i, d := range docs {
+		texts[i] = d.Text
+	}
+
+	results, errs := c.ClassifyBatch(ctx, texts)
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	for i, d := range docs {
+		p.actual = append(p.actual, evaluatedClass(d))
+		if errs[i] != nil {
+			p.predicted = append(p.predicted, "")
+			continue
+		}
+		p.predicted = append(p.predicted, results[i].Class)
+	}
+
+	return nil
+}
+
+func (p *predictions) accuracy() float64 {
+	if len(p.actual) == 0 {
+		return 0
+	}
+
+	var correct int
+	for i := range p.actual {
+		if p.actual[i] == p.predicted[i] {
+			correct++
+		}
+	}
+
+	return float64(correct) / float64(len(p.actual))
+}
+
+func (p *predictions) evaluation() entity.Evaluation {
+	labelsMap := map[string]struct{}{}
+	for i := range p.actual {
+		labelsMap[p.actual[i]] = struct{}{}
+		if p.predicted[i] != "" {
+			labelsMap[p.predicted[i]] = struct{}{}
+		}
+	}
+
+	labels := make([]string, 0, len(labelsMap))
+	for l := range labelsMap {
+		labels = append(labels, l)
+	}
+	sort.Strings(labels)
+
+	indexes := make(map[string]int, len(labels))
+	for i, l := range labels {
+		indexes[l] = i
+	}
+
+	e := entity.Evaluation{
+		Documents:       len(p.actual),
+		Accuracy:        p.accuracy(),
+		Labels:          labels,
+		ConfusionMatrix: make([][]int, len(labels)),
+	}
+
+	for i := range e.ConfusionMatrix {
+		e.ConfusionMatrix[i] = make([]int, len(labels))
+	}
+
+	support := make([]int, len(labels))
+	predicted := make([]int, len(labels))
+
+	for i := range p.actual {
+		a := indexes[p.actual[i]]
+		support[a]++
+
+		if p.predicted[i] == "" {
+			e.Unknown++
+			continue
+		}
+
+		pr := indexes[p.predicted[i]]
+		predicted[pr]++
+
+		e.ConfusionMatrix[a][pr]++
+	}
+
+	for i, l := range labels {
+		m := entity.ClassMetrics{
+			Class:   l,
+			Support: support[i],
+		}
+
+		tp := e.ConfusionMatrix[i][i]
+
+		if predicted[i] > 0 {
+			m.Precision = float64(tp) / float64(predicted[i])
+		}
+		if support[i] > 0 {
+			m.Recall = float64(tp) / float64(support[i])
+		}
+		if m.Precision+m.Recall > 0 {
+			m.F1 = 2 * m.Precision * m.Recall / (m.Precision + m.Recall)
+		}
+
+		e.Classes = append(e.Classes, m)
+	}
+
+	return e
+}
@@ -0,0 +1,178 @@
+package classifier
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/dimuls/classifier/entity"
+)
+
+// newEvaluationDocs returns documents of two classes, a documents of class a
+// and b documents of class b.
+func newEvaluationDocs(a, b int) []entity.Document {
+	var docs []entity.Document
+
+	for i := 0; i < a; i++ {
+		docs = append(docs, entity.Document{
+			Class: "a",
+			Text:  "alpha apple a" + strconv.Itoa(i),
+		})
+	}
+
+	for i := 0; i < b; i++ {
+		docs = append(docs, entity.Document{
+			Class: "b",
+			Text:  "beta banana b" + strconv.Itoa(i),
+		})
+	}
+
+	return docs
+}
+
+func TestStratifiedFolds(t *testing.T) {
+	docs := newEvaluationDocs(10, 5)
+
+	folds := stratifiedFolds(docs, 5, 1)
+
+	if len(folds) != 5 {
+		t.Fatalf("got %d folds, want 5", len(folds))
+	}
+
+	seen := map[string]int{}
+
+	for i, fold := range folds {
+		classes := map[string]int{}
+		for _, d := range fold {
+			classes[d.Class]++
+			seen[d.Text]++
+		}
+
+		// Every fold keeps 2:1 class proportion.
+		if classes["a"] != 2 || classes["b"] != 1 {
+			t.Errorf("fold %d has %d documents of class a and %d of "+
+				"class b, want 2 and 1", i, classes["a"], classes["b"])
+		}
+	}
+
+	for _, d := range docs {
+		if seen[d.Text] != 1 {
+			t.Errorf("document %q is in %d folds, want 1", d.Text,
+				seen[d.Text])
+		}
+	}
+
+	if !reflect.DeepEqual(stratifiedFolds(docs, 5, 1), folds) {
+		t.Error("folds differ for the same seed")
+	}
+}
+
+func TestStratifiedFoldsSizes(t *testing.T) {
+	folds := stratifiedFolds(newEvaluationDocs(4, 4), 3, 1)
+
+	for i, fold := range folds {
+		if len(fold) < 2 || len(fold) > 3 {
+			t.Errorf("fold %d has %d documents, want 2 or 3", i, len(fold))
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	c := newTrainedClassifier(t, newEvaluationDocs(3, 3))
+
+	e, err := c.Evaluate(context.Background(), []entity.Document{
+		{Class: "a", Text: "alpha"},
+		{Class: "a", Text: "banana"},
+		{Class: "b", Text: "beta"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e.Documents != 3 || e.Accuracy != 2.0/3 {
+		t.Errorf("got %d documents with accuracy %v, want 3 documents "+
+			"with accuracy %v", e.Documents, e.Accuracy, 2.0/3)
+	}
+
+	want := [][]int{{1, 1}, {0, 1}}
+	if !reflect.DeepEqual(e.Labels, []string{"a", "b"}) ||
+		!reflect.DeepEqual(e.ConfusionMatrix, want) {
+		t.Errorf("got labels %q and confusion matrix %v, want [a b] and %v",
+			e.Labels, e.ConfusionMatrix, want)
+	}
+
+	if m := e.Classes[1]; m.Precision != 0.5 || m.Recall != 1 {
+		t.Errorf("got precision %v and recall %v of class b, want 0.5 "+
+			"and 1", m.Precision, m.Recall)
+	}
+}
+
+func TestCrossValidate(t *testing.T) {
+	c := NewClassifier(fieldsWordsExtractor{})
+
+	docs := newEvaluationDocs(6, 6)
+
+	e, err := c.CrossValidate(context.Background(), docs,
+		entity.EvaluationOptions{Folds: 3, Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e.Folds != 3 || len(e.FoldAccuracies) != 3 {
+		t.Errorf("got %d folds and %d fold accuracies, want 3", e.Folds,
+			len(e.FoldAccuracies))
+	}
+	if e.Documents != len(docs) || e.Accuracy != 1 {
+		t.Errorf("got %d documents with accuracy %v, want %d documents "+
+			"with accuracy 1", e.Documents, e.Accuracy, len(docs))
+	}
+
+	if c.Trained() {
+		t.Error("cross-validation trains classifier")
+	}
+
+	e, err = c.CrossValidate(context.Background(), docs,
+		entity.EvaluationOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Folds != entity.DefaultFolds {
+		t.Errorf("got %d folds, want %d", e.Folds, entity.DefaultFolds)
+	}
+}
+
+func TestCrossValidateInvalid(t *testing.T) {
+	c := NewClassifier(fieldsWordsExtractor{})
+
+	tests := map[string]struct {
+		docs    []entity.Document
+		options entity.EvaluationOptions
+	}{
+		"one fold": {
+			docs:    newEvaluationDocs(3, 3),
+			options: entity.EvaluationOptions{Folds: 1},
+		},
+		"negative folds": {
+			docs:    newEvaluationDocs(3, 3),
+			options: entity.EvaluationOptions{Folds: -2},
+		},
+		"more folds than documents": {
+			docs:    newEvaluationDocs(2, 2),
+			options: entity.EvaluationOptions{Folds: 5},
+		},
+		"document without class": {
+			docs: append(newEvaluationDocs(3, 3),
+				entity.Document{Text: "gamma"}),
+			options: entity.EvaluationOptions{Folds: 2},
+		},
+	}
+
+	for name, test := range tests {
+		_, err := c.CrossValidate(context.Background(), test.docs,
+			test.options)
+		if err == nil {
+			t.Errorf("%s: cross-validation succeeded", name)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package classifier
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/dimuls/classifier/entity"
+)
+
+// Explain classifies text and returns top words contributions to every class.
+// All contributions are returned if top is not positive.
+func (c *Classifier) Explain(ctx context.Context, text string, top int) (
+	entity.Explanation, error) {
+
+	if !c.Trained() {
+		return entity.Explanation{}, errors.New("classifier is not trained")
+	}
+
+	words, err := c.extractWords(ctx, text, c.currentExtraction())
+	if err != nil {
+		return entity.Explanation{}, errors.New(
+			"failed to extract words from text: " + err.Error())
+	}
+
+	if len(words) == 0 {
+		return entity.Explanation{}, errors.New("no words extracted from text")
+	}
+
+	c.classifierMutex.RLock()
+	defer c.classifierMutex.RUnlock()
+
+	scores, i, strict := c.classifier.LogScores(words)
+	freqs := c.classifier.WordFrequencies(words)
+	classes := c.classifier.Classes
+	known := c.knownWords()
+
+	exp := entity.Explanation{
+		Classification: newClassification(classes, scores, i, strict),
+	}
+
+	exp.Classification.KnownWordsRatio = c.knownWordsRatio(words)
+	c.abstain(&exp.Classification)
+
+	for _, w := range words {
+		if isNGram(w) {
+			continue
+		}
+		if _, isKnown := known[w]; !isKnown {
+			exp.UnknownWords = append(exp.UnknownWords, w)
+		}
+	}
+
+	for ci, class := range classes {
+		var contribs []entity.WordContribution
+
+		for wi, w := range words {
+			if _, isKnown := known[w]; !isKnown {
+				continue
+			}
+			contribs = append(contribs, entity.WordContribution{
+				Word:           w,
+				LogProbability: math.Log(freqs[ci][wi]),
+			})
+		}
+
+		sort.Slice(contribs, func(i, j int) bool {
+			return contribs[i].LogProbability > contribs[j].LogProbability
+		})
+
+		if top > 0 && len(contribs) > top {
+			contribs = contribs[:top]
+		}
+
+		exp.Classes = append(exp.Classes, entity.ClassExplanation{
+			Class: string(class),
+			Words: contribs,
+		})
+	}
+
+	return exp, nil
+}
+
+// knownWords returns words known to classifier. Should be called with
+// classifier mutex locked.
+func (c *Classifier) knownWords() map[string]struct{} {
+	c.knownWordsMutex.Lock()
+	defer c.knownWordsMutex.Unlock()
+
+	if c.knownWordsCache != nil {
+		return c.knownWordsCache
+	}
+
+	known := map[string]struct{}{}
+
+	for _, class := range c.classifier.Classes {
+		for w := range c.classifier.WordsByClass(class) {
+			known[w] = struct{}{}
+		}
+	}
+
+	c.knownWordsCache = known
+
+	return known
+}
@@ -0,0 +1,204 @@
+package classifier
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jbrukh/bayesian"
+
+	"github.com/dimuls/classifier/entity"
+)
+
+// Export returns portable representation of trained classifier.
+func (c *Classifier) Export() (entity.ModelExport, error) {
+	// Learning changes classifier in place, so lock is held until it's
+	// serialized.
+	c.classifierMutex.RLock()
+	defer c.classifierMutex.RUnlock()
+
+	if c.classifier == nil {
+		return entity.ModelExport{}, errors.New("classifier is not trained")
+	}
+
+	m, err := newBayesianModel(c.classifier)
+	if err != nil {
+		return entity.ModelExport{}, err
+	}
+
+	e := entity.ModelExport{
+		FormatVersion:  entity.ModelExportFormatVersion,
+		Created:        time.Now(),
+		WordsExtractor: c.trainedWith,
+		Documents:      m.Learned,
+		SeenDocuments:  m.Seen,
+		TfIdf:          m.TfIdf,
+		TfIdfConverted: m.DidConvertTfIdf,
+		NGrams:         c.extraction.nGrams,
+		Extraction:     c.extraction.options,
+	}
+
+	for _, class := range m.Classes {
+		d := m.Datas[class]
+
+		ce := entity.ClassExport{
+			Class: string(class),
+			Total: d.Total,
+			Words: d.Freqs,
+		}
+
+		if m.TfIdf {
+			ce.TermFrequencies = d.FreqTfs
+		}
+
+		e.Classes = append(e.Classes, ce)
+	}
+
+	return e, nil
+}
+
+// Import replaces classifier by exported one. Export made with different
+// words extractor is imported with warning.
+func (c *Classifier) Import(e entity.ModelExport) error {
+	classifier, err := importClassifier(e)
+	if err != nil {
+		return err
+	}
+
+	ex, err := c.restoreExtraction(e.Extraction, e.TfIdf, e.NGrams)
+	if err != nil {
+		return err
+	}
+
+	if !sameWordsExtractor(e.WordsExtractor,
+		describeWordsExtractor(ex.wordsExtractor)) {
+		c.log.Warning("imported classifier is trained with different " +
+			"words extractor")
+	}
+
+	c.classifierMutex.Lock()
+	c.classifier = classifier
+	c.extraction = ex
+	c.trainedWith = e.WordsExtractor
+	c.knownWordsCache = nil
+	c.version = c.newVersion()
+	c.classifierMutex.Unlock()
+
+	return nil
+}
+
+func importClassifier(e entity.ModelExport) (*bayesian.Classifier, error) {
+	if e.FormatVersion != entity.ModelExportFormatVersion {
+		return nil, errors.New("unsupported format version " +
+			strconv.Itoa(e.FormatVersion))
+	}
+
+	if e.NGrams < 0 || e.NGrams > maxNGrams {
+		return nil, errors.New("unsupported n-grams length " +
+			strconv.Itoa(e.NGrams))
+	}
+
+	if len(e.Classes) < 2 {
+		return nil, errors.New("at least two classes required")
+	}
+
+	// bayesian classifier panics classifying by TF-IDF classifier which
+	// isn't converted.
+	if e.TfIdf && !e.TfIdfConverted {
+		return nil, errors.New("TF-IDF classifier is not converted")
+	}
+
+	// Class priors are proportional to totals, so at least one of them
+	// should be positive.
+	var total int
+
+	for _, ce := range e.Classes {
+		if ce.Total < 0 {
+			return nil, errors.New("negative total of class " + ce.Class)
+		}
+		if ce.Total == 0 && len(ce.Words) > 0 {
+			return nil, errors.New("zero total of class " + ce.Class +
+				" with words")
+		}
+		total += ce.Total
+	}
+
+	if total == 0 {
+		return nil, errors.New("all classes totals are zero")
+	}
+
+	m := &bayesianModel{
+		Learned:         e.Documents,
+		Seen:            e.SeenDocuments,
+		Datas:           map[bayesian.Class]*classData{},
+		TfIdf:           e.TfIdf,
+		DidConvertTfIdf: e.TfIdfConverted,
+	}
+
+	for _, ce := range e.Classes {
+		class := bayesian.Class(ce.Class)
+
+		if _, exists := m.Datas[class]; exists {
+			return nil, errors.New("duplicate class " + ce.Class)
+		}
+
+		d := newClassData()
+		d.Total = ce.Total
+
+		for w, count := range ce.Words {
+			d.Freqs[w] = count
+		}
+		for w, tfs := range ce.TermFrequencies {
+			d.FreqTfs[w] = tfs
+		}
+
+		m.Classes = append(m.Classes, class)
+		m.Datas[class] = d
+	}
+
+	return m.classifier()
+}
+
+// descriptionWordsExtractor is a words extractor which only describes other
+// words extractor. It's used to convert model files without running words
+// extractor they are trained with.
+type descriptionWordsExtractor entity.WordsExtractorInfo
+
+func (we descriptionWordsExtractor) ExtractWords(text string) (
+	[]string, error) {
+	return nil, errors.New("words extractor is not available")
+}
+
+func (we descriptionWordsExtractor) Describe() entity.WordsExtractorInfo {
+	return entity.WordsExtractorInfo(we)
+}
+
+// ExportFile exports classifier saved to path.
+func ExportFile(path string) (entity.ModelExport, error) {
+	meta, err := ReadMetadata(path)
+	if err != nil && !os.IsNotExist(err) {
+		return entity.ModelExport{}, err
+	}
+
+	c := NewClassifier(descriptionWordsExtractor(meta.WordsExtractor))
+
+	err = c.Load(path)
+	if err != nil {
+		return entity.ModelExport{}, err
+	}
+
+	return c.Export()
+}
+
+// ImportFile saves exported classifier to path.
+func ImportFile(e entity.ModelExport, path string) error {
+	c := NewClassifier(descriptionWordsExtractor(e.WordsExtractor))
+
+	err := c.Import(e)
+	if err != nil {
+		return err
+	}
+
+	return c.Save(path)
+}
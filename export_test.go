@@ -0,0 +1,141 @@
+package classifier
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/dimuls/classifier/entity"
+)
+
+func TestExportImport(t *testing.T) {
+	c := newTrainedClassifier(t, testDocs)
+
+	e, err := c.Export()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e.Documents != len(testDocs) || len(e.Classes) != 2 {
+		t.Fatalf("got export of %d documents and %d classes, want %d "+
+			"documents and 2 classes", e.Documents, len(e.Classes),
+			len(testDocs))
+	}
+
+	// Export survives JSON encoding.
+	data, err := json.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded entity.ModelExport
+
+	err = json.Unmarshal(data, &decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ic := NewClassifier(fieldsWordsExtractor{})
+
+	err = ic.Import(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, text := range []string{"football goal", "election law",
+		"vote match"} {
+
+		want, err := c.Classify(context.Background(), text)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := ic.Classify(context.Background(), text)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(got.Probabilities, want.Probabilities) {
+			t.Errorf("imported classifier probabilities of %q are %v, "+
+				"want %v", text, got.Probabilities, want.Probabilities)
+		}
+	}
+
+	ie, err := ic.Export()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ie.Created = e.Created
+	ie.SeenDocuments = e.SeenDocuments
+
+	if !reflect.DeepEqual(ie, e) {
+		t.Errorf("got export of imported classifier %+v, want %+v", ie, e)
+	}
+}
+
+func TestImportInvalid(t *testing.T) {
+	valid := func() entity.ModelExport {
+		return entity.ModelExport{
+			FormatVersion: entity.ModelExportFormatVersion,
+			Documents:     2,
+			Classes: []entity.ClassExport{
+				{Class: "a", Total: 1, Words: map[string]float64{"x": 1}},
+				{Class: "b", Total: 1, Words: map[string]float64{"y": 1}},
+			},
+		}
+	}
+
+	tests := map[string]func(e *entity.ModelExport){
+		"unsupported format version": func(e *entity.ModelExport) {
+			e.FormatVersion++
+		},
+		"unsupported n-grams length": func(e *entity.ModelExport) {
+			e.NGrams = maxNGrams + 1
+		},
+		"single class": func(e *entity.ModelExport) {
+			e.Classes = e.Classes[:1]
+		},
+		"duplicate class": func(e *entity.ModelExport) {
+			e.Classes[1].Class = "a"
+		},
+		"not converted TF-IDF": func(e *entity.ModelExport) {
+			e.TfIdf = true
+		},
+		"negative total": func(e *entity.ModelExport) {
+			e.Classes[0].Total = -1
+		},
+		"zero total with words": func(e *entity.ModelExport) {
+			e.Classes[0].Total = 0
+		},
+		"zero totals": func(e *entity.ModelExport) {
+			for i := range e.Classes {
+				e.Classes[i].Total = 0
+				e.Classes[i].Words = nil
+			}
+		},
+	}
+
+	c := NewClassifier(fieldsWordsExtractor{})
+
+	err := c.Import(valid())
+	if err != nil {
+		t.Fatalf("failed to import valid export: %v", err)
+	}
+
+	for name, change := range tests {
+		e := valid()
+		change(&e)
+
+		c := NewClassifier(fieldsWordsExtractor{})
+
+		err := c.Import(e)
+		if err == nil {
+			t.Errorf("export with %s is imported", name)
+		}
+		if c.Trained() {
+			t.Errorf("export with %s is swapped in", name)
+		}
+	}
+}
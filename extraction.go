@@ -0,0 +1,180 @@
+package classifier
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+
+	"github.com/dimuls/classifier/entity"
+)
+
+// WordsExtractorFactory creates words extractor configured by options.
+type WordsExtractorFactory func(o entity.ExtractionOptions) (WordsExtractor,
+	error)
+
+// StaticWordsExtractorFactory returns factory of words extractor which can't
+// be configured. Factory fails for options other than default ones.
+func StaticWordsExtractorFactory(we WordsExtractor) WordsExtractorFactory {
+	return func(o entity.ExtractionOptions) (WordsExtractor, error) {
+		if !o.IsDefault() {
+			return nil, errors.New("words extractor can't be configured")
+		}
+		return we, nil
+	}
+}
+
+// extraction describes how features are extracted from text for classifier.
+type extraction struct {
+	wordsExtractor WordsExtractor
+
+	// options are extraction options classifier is trained with. Words
+	// extractor may be created with default options if it can't be
+	// created with them.
+	options entity.ExtractionOptions
+
+	// counts is true if words are extracted with repetitions.
+	counts bool
+
+	// nGrams is a maximal length of word n-grams. Values less than 2 mean
+	// single words only.
+	nGrams int
+}
+
+// createWordsExtractor creates words extractor configured by o. Text is
+// normalized before words extraction if normalization is configured, so
+// factory doesn't see normalization options.
+func (c *Classifier) createWordsExtractor(o entity.ExtractionOptions) (
+	WordsExtractor, error) {
+
+	no := o.Normalization
+	o.Normalization = entity.NormalizationOptions{}
+
+	we, err := c.newWordsExtractor(o)
+	if err != nil {
+		return nil, err
+	}
+
+	if no.IsDefault() {
+		return we, nil
+	}
+
+	return NewNormalizingWordsExtractor(we, no), nil
+}
+
+// newExtraction creates features extraction configured by cfg.
+func (c *Classifier) newExtraction(cfg entity.ModelConfig) (extraction,
+	error) {
+
+	we, err := c.createWordsExtractor(cfg.Extraction)
+	if err != nil {
+		return extraction{}, errors.New("failed to create words extractor: " +
+			err.Error())
+	}
+
+	return extraction{
+		wordsExtractor: we,
+		options:        cfg.Extraction,
+		counts:         cfg.TfIdf,
+		nGrams:         cfg.NGrams,
+	}, nil
+}
+
+// restoreExtraction creates features extraction of classifier trained with
+// options. Words extractor with default options and the same normalization
+// is used if words extractor can't be created with them.
+func (c *Classifier) restoreExtraction(o entity.ExtractionOptions,
+	counts bool, nGrams int) (extraction, error) {
+
+	we, err := c.createWordsExtractor(o)
+	if err != nil {
+		c.log.WithError(err).Warning(
+			"failed to create words extractor, using default one")
+
+		we, err = c.createWordsExtractor(entity.ExtractionOptions{
+			Normalization: o.Normalization,
+		})
+		if err != nil {
+			return extraction{}, errors.New(
+				"failed to create words extractor: " + err.Error())
+		}
+	}
+
+	return extraction{
+		wordsExtractor: we,
+		options:        o,
+		counts:         counts,
+		nGrams:         nGrams,
+	}, nil
+}
+
+// sameExtraction reports whether features extractions are configured the
+// same way.
+func sameExtraction(a, b extraction) bool {
+	return a.counts == b.counts && a.nGrams == b.nGrams &&
+		reflect.DeepEqual(a.options, b.options) &&
+		reflect.DeepEqual(describeWordsExtractor(a.wordsExtractor),
+			describeWordsExtractor(b.wordsExtractor))
+}
+
+// currentExtraction returns features extraction of current classifier.
+func (c *Classifier) currentExtraction() extraction {
+	c.classifierMutex.RLock()
+	defer c.classifierMutex.RUnlock()
+
+	return c.extraction
+}
+
+// extractWords extracts features from text. Words are extracted with
+// repetitions if counts is enabled and words extractor supports it. Word
+// n-grams are appended to words if they are enabled. Extraction fails with
+// ErrExtractionTimeout or ErrExtractionCanceled when ctx is done.
+func (c *Classifier) extractWords(ctx context.Context, text string,
+	e extraction) ([]string, error) {
+
+	if e.wordsExtractor == nil {
+		return nil, errors.New("classifier is not trained")
+	}
+
+	if e.nGrams < 2 {
+		if e.counts && extractsTokens(e.wordsExtractor) {
+			return extractTokensContext(ctx, e.wordsExtractor, text)
+		}
+		return extractWordsContext(ctx, e.wordsExtractor, text)
+	}
+
+	// Words and phrases are extracted at once, so text is analyzed once if
+	// words extractor supports it.
+	words, phrases, err := extractTokensPhrasesContext(ctx, e.wordsExtractor,
+		text)
+	if err != nil {
+		return nil, err
+	}
+
+	if !e.counts {
+		words = unique(words)
+		sort.Strings(words)
+	}
+
+	grams := nGrams(phrases, e.nGrams)
+
+	if !e.counts {
+		grams = unique(grams)
+	}
+
+	return append(words, grams...), nil
+}
+
+// wordsExtractorMismatch reports whether classifier is served with words
+// extractor which differs from one it's trained with.
+func (c *Classifier) wordsExtractorMismatch() bool {
+	c.classifierMutex.RLock()
+	defer c.classifierMutex.RUnlock()
+
+	if c.classifier == nil {
+		return false
+	}
+
+	return !sameWordsExtractor(c.trainedWith,
+		describeWordsExtractor(c.extraction.wordsExtractor))
+}
@@ -0,0 +1,86 @@
+package classifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/dimuls/classifier/entity"
+)
+
+// feedbackLogSuffix is appended to model file path to get feedback log file
+// path.
+const feedbackLogSuffix = ".feedback"
+
+// FeedbackLog is an append-only log of classification feedback. Every
+// feedback is stored as a JSON line.
+type FeedbackLog struct {
+	path       string
+	classifier *Classifier
+	mutex      sync.Mutex
+}
+
+func NewFeedbackLog(path string, c *Classifier) *FeedbackLog {
+	return &FeedbackLog{
+		path:       path,
+		classifier: c,
+	}
+}
+
+// Record appends feedback to log. Classifier learns text with correct class
+// if learn is true.
+func (fl *FeedbackLog) Record(ctx context.Context, f entity.Feedback,
+	learn bool) error {
+
+	if f.Correct == "" {
+		return errors.New("correct class is not specified")
+	}
+
+	if f.Time.IsZero() {
+		f.Time = time.Now()
+	}
+
+	err := fl.append(f)
+	if err != nil {
+		return errors.New("failed to append feedback to log: " + err.Error())
+	}
+
+	if learn {
+		err = fl.classifier.Learn(ctx, []entity.Document{{
+			Text:  f.Text,
+			Class: f.Correct,
+		}})
+		if err != nil {
+			return errors.New("failed to learn feedback: " + err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (fl *FeedbackLog) append(f entity.Feedback) error {
+	line, err := json.Marshal(f)
+	if err != nil {
+		return errors.New("failed to JSON marshal feedback: " + err.Error())
+	}
+
+	fl.mutex.Lock()
+	defer fl.mutex.Unlock()
+
+	file, err := os.OpenFile(fl.path,
+		os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return errors.New("failed to open file: " + err.Error())
+	}
+
+	_, err = file.Write(append(line, '\n'))
+	if err != nil {
+		file.Close()
+		return errors.New("failed to write file: " + err.Error())
+	}
+
+	return file.Close()
+}
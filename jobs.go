@@ -0,0 +1,259 @@
+package classifier
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/dimuls/classifier/entity"
+)
+
+// maxFinishedJobs is a number of finished training jobs kept for reporting.
+const maxFinishedJobs = 100
+
+// trainingJobs runs training jobs of classifier one by one in order of their
+// start.
+type trainingJobs struct {
+	classifier *Classifier
+
+	// trained is called with job ID after every successful training.
+	trained func(jobID string)
+
+	jobs      map[string]*trainingJob
+	jobsMutex sync.Mutex
+
+	// lastDone is closed when the last started job is finished.
+	lastDone chan struct{}
+
+	waitGroup sync.WaitGroup
+
+	log *logrus.Entry
+}
+
+type trainingJob struct {
+	job    entity.TrainingJob
+	cancel context.CancelFunc
+}
+
+func newTrainingJobs(c *Classifier, trained func(jobID string),
+	log *logrus.Entry) *trainingJobs {
+
+	lastDone := make(chan struct{})
+	close(lastDone)
+
+	return &trainingJobs{
+		classifier: c,
+		trained:    trained,
+		jobs:       map[string]*trainingJob{},
+		lastDone:   lastDone,
+		log:        log,
+	}
+}
+
+// Start queues training job and returns its initial state.
+func (tj *trainingJobs) Start(docs []entity.Document) entity.TrainingJob {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	j := &trainingJob{
+		job: entity.TrainingJob{
+			ID:      newJobID(),
+			State:   entity.TrainingJobQueued,
+			Total:   len(docs),
+			Created: time.Now(),
+		},
+		cancel: cancel,
+	}
+
+	done := make(chan struct{})
+
+	tj.jobsMutex.Lock()
+	tj.jobs[j.job.ID] = j
+	tj.pruneJobs()
+	job := j.job
+	prevDone := tj.lastDone
+	tj.lastDone = done
+	tj.jobsMutex.Unlock()
+
+	tj.waitGroup.Add(1)
+	go func() {
+		defer tj.waitGroup.Done()
+		defer close(done)
+		defer cancel()
+		tj.run(ctx, j, docs, prevDone)
+	}()
+
+	return job
+}
+
+// run waits for previous job to finish and trains classifier. Job cancelled
+// while queued is finished immediately, but still waits for previous job to
+// keep jobs order.
+func (tj *trainingJobs) run(ctx context.Context, j *trainingJob,
+	docs []entity.Document, prevDone <-chan struct{}) {
+
+	select {
+	case <-prevDone:
+	case <-ctx.Done():
+		tj.finish(j, ctx.Err())
+		<-prevDone
+		return
+	}
+
+	if ctx.Err() != nil {
+		tj.finish(j, ctx.Err())
+		return
+	}
+
+	tj.jobsMutex.Lock()
+	j.job.State = entity.TrainingJobRunning
+	j.job.Started = time.Now()
+	tj.jobsMutex.Unlock()
+
+	err := tj.classifier.Train(ctx, docs, func(processed int) {
+		tj.jobsMutex.Lock()
+		j.job.Processed = processed
+		tj.jobsMutex.Unlock()
+	})
+
+	if err == nil && tj.trained != nil {
+		tj.trained(j.job.ID)
+	}
+
+	tj.finish(j, err)
+}
+
+func (tj *trainingJobs) finish(j *trainingJob, err error) {
+	tj.jobsMutex.Lock()
+	defer tj.jobsMutex.Unlock()
+
+	j.job.Finished = time.Now()
+
+	switch {
+	case err == nil:
+		j.job.State = entity.TrainingJobSucceeded
+	case err == context.Canceled:
+		j.job.State = entity.TrainingJobCancelled
+	default:
+		j.job.State = entity.TrainingJobFailed
+		j.job.Error = err.Error()
+		tj.log.WithError(err).WithField("job_id", j.job.ID).
+			Error("training job failed")
+	}
+}
+
+// pruneJobs removes the oldest finished jobs. Should be called with jobs
+// mutex locked.
+func (tj *trainingJobs) pruneJobs() {
+	var finished []*trainingJob
+	for _, j := range tj.jobs {
+		if j.job.Done() {
+			finished = append(finished, j)
+		}
+	}
+
+	if len(finished) <= maxFinishedJobs {
+		return
+	}
+
+	sort.Slice(finished, func(i, k int) bool {
+		return finished[i].job.Finished.Before(finished[k].job.Finished)
+	})
+
+	for _, j := range finished[:len(finished)-maxFinishedJobs] {
+		delete(tj.jobs, j.job.ID)
+	}
+}
+
+// Job returns current state of job.
+func (tj *trainingJobs) Job(id string) (entity.TrainingJob, bool) {
+	tj.jobsMutex.Lock()
+	defer tj.jobsMutex.Unlock()
+
+	j, exists := tj.jobs[id]
+	if !exists {
+		return entity.TrainingJob{}, false
+	}
+
+	return j.snapshot(), true
+}
+
+// Jobs returns current states of jobs ordered by creation time.
+func (tj *trainingJobs) Jobs() []entity.TrainingJob {
+	tj.jobsMutex.Lock()
+	defer tj.jobsMutex.Unlock()
+
+	jobs := make([]entity.TrainingJob, 0, len(tj.jobs))
+	for _, j := range tj.jobs {
+		jobs = append(jobs, j.snapshot())
+	}
+
+	sort.Slice(jobs, func(i, k int) bool {
+		return jobs[i].Created.Before(jobs[k].Created)
+	})
+
+	return jobs
+}
+
+// Cancel cancels job. It returns false if job doesn't exist.
+func (tj *trainingJobs) Cancel(id string) bool {
+	tj.jobsMutex.Lock()
+	defer tj.jobsMutex.Unlock()
+
+	j, exists := tj.jobs[id]
+	if !exists {
+		return false
+	}
+
+	j.cancel()
+
+	return true
+}
+
+// CancelAll cancels all jobs.
+func (tj *trainingJobs) CancelAll() {
+	tj.jobsMutex.Lock()
+	defer tj.jobsMutex.Unlock()
+
+	for _, j := range tj.jobs {
+		j.cancel()
+	}
+}
+
+// Wait waits for all jobs to finish.
+func (tj *trainingJobs) Wait() {
+	tj.waitGroup.Wait()
+}
+
+// snapshot returns job state with actual elapsed time. Should be called with
+// jobs mutex locked.
+func (j *trainingJob) snapshot() entity.TrainingJob {
+	job := j.job
+
+	switch {
+	case job.Started.IsZero():
+	case job.Finished.IsZero():
+		job.ElapsedSeconds = time.Since(job.Started).Seconds()
+	default:
+		job.ElapsedSeconds = job.Finished.Sub(job.Started).Seconds()
+	}
+
+	if job.ElapsedSeconds > 0 {
+		job.DocumentsPerSecond = float64(job.Processed) / job.ElapsedSeconds
+	}
+
+	return job
+}
+
+func newJobID() string {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		panic("failed to read random bytes: " + err.Error())
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,304 @@
+package classifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/dimuls/classifier/entity"
+)
+
+// Model is a named classifier persisted to its own file.
+type Model struct {
+	*Classifier
+
+	name        string
+	filePath    string
+	feedbackLog *FeedbackLog
+	jobs        *trainingJobs
+	snapshots   *snapshots
+
+	// saveMutex serializes saves and guards save state.
+	saveMutex    sync.Mutex
+	savedVersion int64
+	lastSaved    time.Time
+	deleted      bool
+
+	log *logrus.Entry
+}
+
+// configSuffix is appended to model file path to get its config file path.
+const configSuffix = ".config"
+
+var errModelDeleted = errors.New("model is deleted")
+
+// newModel creates model which keeps keepSnapshots last snapshots of trained
+// classifier. Model uses config until it's configured.
+func newModel(name string, filePath string, f WordsExtractorFactory,
+	keepSnapshots int, config entity.ModelConfig) *Model {
+
+	c := NewConfigurableClassifier(f)
+	c.config = copyConfig(config)
+
+	m := &Model{
+		Classifier:  c,
+		name:        name,
+		filePath:    filePath,
+		feedbackLog: NewFeedbackLog(filePath+feedbackLogSuffix, c),
+		snapshots:   newSnapshots(filePath+snapshotsDirSuffix, keepSnapshots),
+		log: logrus.WithFields(logrus.Fields{
+			"subsystem": "model",
+			"model":     name,
+		}),
+	}
+
+	m.jobs = newTrainingJobs(c, m.saveAfterTrain, m.log)
+
+	return m
+}
+
+func (m *Model) saveAfterTrain(jobID string) {
+	m.saveMutex.Lock()
+	defer m.saveMutex.Unlock()
+
+	if m.deleted {
+		return
+	}
+
+	// Snapshot is created from the saved model, so it isn't affected by
+	// changes made after save.
+	ec, err := m.save()
+	if err != nil {
+		m.log.WithError(err).Error("failed to save model after training")
+		return
+	}
+
+	if ec == nil {
+		return
+	}
+
+	err = m.snapshots.Create(ec, jobID)
+	if err != nil {
+		m.log.WithError(err).Error("failed to create model snapshot")
+	}
+}
+
+// Snapshots returns model snapshots ordered from the newest to the oldest.
+func (m *Model) Snapshots() ([]entity.Snapshot, error) {
+	return m.snapshots.List()
+}
+
+// ActivateSnapshot atomically replaces model by snapshot and saves it.
+func (m *Model) ActivateSnapshot(id string) error {
+	err := m.snapshots.Restore(m.Classifier, id)
+	if err != nil {
+		return err
+	}
+
+	return m.Save()
+}
+
+// EvaluateSnapshot evaluates snapshot model on documents and saves
+// evaluation to snapshot.
+func (m *Model) EvaluateSnapshot(ctx context.Context, id string,
+	docs []entity.Document) (entity.Evaluation, error) {
+
+	return m.snapshots.Evaluate(ctx, m.Classifier, id, docs)
+}
+
+// Import replaces model by exported one and saves it.
+func (m *Model) Import(e entity.ModelExport) error {
+	err := m.Classifier.Import(e)
+	if err != nil {
+		return err
+	}
+
+	return m.Save()
+}
+
+func (m *Model) Name() string {
+	return m.name
+}
+
+// RecordFeedback records feedback to model feedback log. Model learns text
+// with correct class if learn is true.
+func (m *Model) RecordFeedback(ctx context.Context, f entity.Feedback,
+	learn bool) error {
+
+	return m.feedbackLog.Record(ctx, f, learn)
+}
+
+// StartTraining queues training job. Jobs of model run one by one.
+func (m *Model) StartTraining(docs []entity.Document) entity.TrainingJob {
+	return m.jobs.Start(docs)
+}
+
+func (m *Model) TrainingJob(id string) (entity.TrainingJob, bool) {
+	return m.jobs.Job(id)
+}
+
+func (m *Model) TrainingJobs() []entity.TrainingJob {
+	return m.jobs.Jobs()
+}
+
+// CancelTrainingJob cancels training job. It returns false if job doesn't
+// exist.
+func (m *Model) CancelTrainingJob(id string) bool {
+	return m.jobs.Cancel(id)
+}
+
+// Wait waits for training jobs to finish.
+func (m *Model) Wait() {
+	m.jobs.Wait()
+}
+
+// Load loads model from its file.
+func (m *Model) Load() error {
+	m.saveMutex.Lock()
+	defer m.saveMutex.Unlock()
+
+	err := m.Classifier.Load(m.filePath)
+	if err != nil {
+		return err
+	}
+
+	m.savedVersion = m.TrainingStatus().LiveVersion
+
+	meta, err := ReadMetadata(m.filePath)
+	if err == nil {
+		m.lastSaved = meta.Created
+	}
+
+	return nil
+}
+
+// LoadConfig loads model config from its file if it exists.
+func (m *Model) LoadConfig() error {
+	f, err := os.Open(m.filePath + configSuffix)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.New("failed to open config file: " + err.Error())
+	}
+
+	defer f.Close()
+
+	var cfg entity.ModelConfig
+
+	err = json.NewDecoder(f).Decode(&cfg)
+	if err != nil {
+		return errors.New("failed to decode config file: " + err.Error())
+	}
+
+	return m.Classifier.SetConfig(cfg)
+}
+
+// SetConfig sets model config and saves it to its file.
+func (m *Model) SetConfig(cfg entity.ModelConfig) error {
+	err := validateConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	cfg = normalizeConfig(cfg)
+
+	m.saveMutex.Lock()
+	defer m.saveMutex.Unlock()
+
+	if m.deleted {
+		return errModelDeleted
+	}
+
+	err = writeFileAtomic(m.filePath+configSuffix, func(w io.Writer) error {
+		return json.NewEncoder(w).Encode(cfg)
+	})
+	if err != nil {
+		return errors.New("failed to write config file: " + err.Error())
+	}
+
+	return m.Classifier.SetConfig(cfg)
+}
+
+// Save saves model to its file.
+func (m *Model) Save() error {
+	m.saveMutex.Lock()
+	defer m.saveMutex.Unlock()
+
+	_, err := m.save()
+	return err
+}
+
+// SaveChanged saves model if it's changed since last save.
+func (m *Model) SaveChanged() error {
+	m.saveMutex.Lock()
+	defer m.saveMutex.Unlock()
+
+	if m.TrainingStatus().LiveVersion == m.savedVersion {
+		return nil
+	}
+
+	_, err := m.save()
+	return err
+}
+
+// markDeleted stops model saves. It waits for running save to finish.
+func (m *Model) markDeleted() {
+	m.saveMutex.Lock()
+	defer m.saveMutex.Unlock()
+
+	m.deleted = true
+}
+
+// save saves model and returns saved encoded classifier. It returns nil if
+// model isn't trained. Should be called with save mutex locked.
+func (m *Model) save() (*encodedClassifier, error) {
+	if m.deleted {
+		return nil, errModelDeleted
+	}
+
+	// Version is taken before encoding, so changes made while saving are
+	// saved next time.
+	version := m.TrainingStatus().LiveVersion
+	if version == 0 {
+		return nil, nil
+	}
+
+	ec, err := m.Classifier.encode()
+	if err != nil || ec == nil {
+		return nil, err
+	}
+
+	err = m.Classifier.saveEncoded(m.filePath, ec)
+	if err != nil {
+		return nil, err
+	}
+
+	m.savedVersion = version
+	m.lastSaved = time.Now()
+
+	return ec, nil
+}
+
+func (m *Model) Status() entity.ModelStatus {
+	m.saveMutex.Lock()
+	savedVersion := m.savedVersion
+	lastSaved := m.lastSaved
+	m.saveMutex.Unlock()
+
+	return entity.ModelStatus{
+		Name:           m.name,
+		Trained:        m.Trained(),
+		TrainingStatus: m.TrainingStatus(),
+		SavedVersion:   savedVersion,
+		LastSaved:      lastSaved,
+
+		WordsExtractorMismatch: m.wordsExtractorMismatch(),
+	}
+}
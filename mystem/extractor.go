@@ -0,0 +1,396 @@
+package mystem
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/dimuls/classifier/entity"
+)
+
+// Name is a name of mystem words extractors in their description.
+const Name = "mystem"
+
+// mystemArgs make mystem print every word on its own line with all its
+// lemmas and their grammatical info. Text between words is printed on its
+// own lines too, so phrases can be split by punctuation.
+var mystemArgs = []string{"-n", "-l", "-i", "-c"}
+
+// Mystem part of speech tags of numerals.
+const (
+	numeralPOS          = "NUM"
+	numeralAdjectivePOS = "ANUM"
+)
+
+// properNameTags are mystem lexeme tags of proper names.
+var properNameTags = map[string]struct{}{
+	"имя": {},
+	"фам": {},
+	"отч": {},
+	"гео": {},
+}
+
+// Filter selects words extracted by mystem.
+type Filter struct {
+	// StopWords are skipped. Built-in stop words are used if it's nil.
+	StopWords *StopWords
+
+	// PartsOfSpeech are mystem part of speech tags of kept words. Empty
+	// keeps all words.
+	PartsOfSpeech []string
+
+	DropProperNames bool
+	DropNumerals    bool
+}
+
+// Extractor extracts words from mystem output and filters them. Mystem is
+// stopped when context of extraction is done.
+type Extractor struct {
+	analyze func(ctx context.Context, text string) ([]string, error)
+	filter  Filter
+
+	partsOfSpeech map[string]struct{}
+}
+
+func newExtractor(analyze func(ctx context.Context, text string) ([]string,
+	error), f Filter) *Extractor {
+
+	if f.StopWords == nil {
+		f.StopWords = BuiltinStopWords()
+	}
+
+	e := &Extractor{
+		analyze: analyze,
+		filter:  f,
+	}
+
+	if len(f.PartsOfSpeech) > 0 {
+		e.partsOfSpeech = map[string]struct{}{}
+		for _, pos := range f.PartsOfSpeech {
+			e.partsOfSpeech[pos] = struct{}{}
+		}
+	}
+
+	return e
+}
+
+// ExtractWords extracts sorted unique words of text.
+func (e *Extractor) ExtractWords(text string) ([]string, error) {
+	return e.ExtractWordsContext(context.Background(), text)
+}
+
+func (e *Extractor) ExtractWordsContext(ctx context.Context, text string) (
+	[]string, error) {
+
+	lines, err := e.lines(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := countTokens(e.extractTokens(lines))
+
+	kws := make([]string, 0, len(counts))
+	for kw := range counts {
+		kws = append(kws, kw)
+	}
+
+	sort.Strings(kws)
+
+	return kws, nil
+}
+
+// ExtractTokens extracts words keeping their order and repetitions. Every
+// alternative lemma of word is kept once per word.
+func (e *Extractor) ExtractTokens(text string) ([]string, error) {
+	return e.ExtractTokensContext(context.Background(), text)
+}
+
+func (e *Extractor) ExtractTokensContext(ctx context.Context, text string) (
+	[]string, error) {
+
+	lines, err := e.lines(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.extractTokens(lines), nil
+}
+
+// ExtractCounts extracts words with their counts in text.
+func (e *Extractor) ExtractCounts(text string) (map[string]int, error) {
+	tokens, err := e.ExtractTokens(text)
+	if err != nil {
+		return nil, err
+	}
+
+	return countTokens(tokens), nil
+}
+
+// ExtractPhrases extracts sequences of words which aren't interrupted by
+// punctuation, line breaks or filtered out words. The first lemma of every
+// word is used.
+func (e *Extractor) ExtractPhrases(text string) ([][]string, error) {
+	return e.ExtractPhrasesContext(context.Background(), text)
+}
+
+func (e *Extractor) ExtractPhrasesContext(ctx context.Context, text string) (
+	[][]string, error) {
+
+	lines, err := e.lines(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.extractPhrases(lines), nil
+}
+
+// ExtractTokensPhrasesContext extracts tokens and phrases running mystem
+// once.
+func (e *Extractor) ExtractTokensPhrasesContext(ctx context.Context,
+	text string) ([]string, [][]string, error) {
+
+	lines, err := e.lines(ctx, text)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return e.extractTokens(lines), e.extractPhrases(lines), nil
+}
+
+func (e *Extractor) Describe() entity.WordsExtractorInfo {
+	settings := map[string]string{
+		"lemmas": "all",
+	}
+
+	if len(e.filter.PartsOfSpeech) > 0 {
+		pos := append([]string(nil), e.filter.PartsOfSpeech...)
+		sort.Strings(pos)
+		settings["parts_of_speech"] = strings.Join(pos, ",")
+	}
+	if e.filter.DropProperNames {
+		settings["drop_proper_names"] = "true"
+	}
+	if e.filter.DropNumerals {
+		settings["drop_numerals"] = "true"
+	}
+
+	return entity.WordsExtractorInfo{
+		Name:          Name,
+		Settings:      settings,
+		StopWordsHash: e.filter.StopWords.Hash(),
+	}
+}
+
+func (e *Extractor) lines(ctx context.Context, text string) ([]string,
+	error) {
+
+	if text == "" {
+		return nil, nil
+	}
+
+	lines, err := e.analyze(ctx, text)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, errors.New("mystem is stopped: " + ctx.Err().Error())
+		}
+		return nil, errors.New("failed to run mystem: " + err.Error())
+	}
+
+	return lines, nil
+}
+
+func (e *Extractor) extractTokens(lines []string) []string {
+	var kws []string
+
+	for _, line := range lines {
+		lemmas, isWord := parseOutputLine(line)
+		if !isWord {
+			continue
+		}
+
+		var words []string
+
+		for _, l := range lemmas {
+			if !e.keep(l) || containsString(words, l.word) {
+				continue
+			}
+			words = append(words, l.word)
+		}
+
+		kws = append(kws, words...)
+	}
+
+	return kws
+}
+
+// extractPhrases splits words to phrases by punctuation, line breaks and
+// filtered out words. The first lemma of every word is used.
+func (e *Extractor) extractPhrases(lines []string) [][]string {
+	var (
+		phrases [][]string
+		phrase  []string
+	)
+
+	for _, line := range lines {
+		lemmas, isWord := parseOutputLine(line)
+
+		if isWord && e.keep(lemmas[0]) {
+			phrase = append(phrase, lemmas[0].word)
+			continue
+		}
+
+		if !isWord && isSpace(line) {
+			continue
+		}
+
+		if len(phrase) > 0 {
+			phrases = append(phrases, phrase)
+			phrase = nil
+		}
+	}
+
+	if len(phrase) > 0 {
+		phrases = append(phrases, phrase)
+	}
+
+	return phrases
+}
+
+// keep reports whether lemma passes filter.
+func (e *Extractor) keep(l lemma) bool {
+	if l.word == "" || e.filter.StopWords.Contains(l.word) {
+		return false
+	}
+
+	if e.partsOfSpeech != nil {
+		if _, exists := e.partsOfSpeech[l.pos]; !exists {
+			return false
+		}
+	}
+
+	if e.filter.DropNumerals &&
+		(l.pos == numeralPOS || l.pos == numeralAdjectivePOS) {
+		return false
+	}
+
+	if e.filter.DropProperNames {
+		for _, tag := range l.tags {
+			if _, exists := properNameTags[tag]; exists {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// lemma is a word lemma with its grammatical info from mystem output.
+type lemma struct {
+	word string
+
+	// pos is a part of speech tag. It's empty if mystem doesn't know it.
+	pos string
+
+	// tags are lexeme tags following part of speech.
+	tags []string
+}
+
+// lineBreak is printed by mystem instead of text between words which contains
+// line break.
+const lineBreak = `\s`
+
+// parseOutputLine parses mystem output line. It returns false if line is a
+// text between words. Word analysis is enclosed in braces, but words without
+// braces are parsed too.
+func parseOutputLine(line string) ([]lemma, bool) {
+	if strings.HasPrefix(line, "{") && strings.HasSuffix(line, "}") {
+		return parseLine(line[1 : len(line)-1]), true
+	}
+
+	if strings.IndexFunc(strings.Replace(line, lineBreak, "", -1),
+		unicode.IsLetter) < 0 {
+		return nil, false
+	}
+
+	return parseLine(line), true
+}
+
+// isSpace reports whether text between words is spaces only, so it doesn't
+// split phrase.
+func isSpace(line string) bool {
+	return line != "" && strings.Trim(line, " \t") == ""
+}
+
+// parseLine parses mystem output line of single word. Line contains
+// alternative lemmas with optional grammatical info, e.g.
+// "сталь=S,жен,неод=(вин,мн|род,ед)|стать=V,нп=прош,мн,изъяв,сов".
+// At least one lemma is always returned.
+func parseLine(line string) []lemma {
+	alts := splitAlternatives(line)
+
+	lemmas := make([]lemma, 0, len(alts))
+
+	for _, alt := range alts {
+		var l lemma
+
+		parts := strings.SplitN(alt, "=", 3)
+
+		l.word = strings.ToLower(strings.TrimRight(parts[0], "?"))
+
+		if len(parts) > 1 {
+			lexeme := strings.Split(parts[1], ",")
+			l.pos = lexeme[0]
+			l.tags = lexeme[1:]
+		}
+
+		lemmas = append(lemmas, l)
+	}
+
+	return lemmas
+}
+
+// splitAlternatives splits line by "|" which is not inside parentheses.
+// Parentheses enclose alternative inflections of lemma.
+func splitAlternatives(line string) []string {
+	var (
+		alts  []string
+		depth int
+		start int
+	)
+
+	for i, r := range line {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case '|':
+			if depth == 0 {
+				alts = append(alts, line[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(alts, line[start:])
+}
+
+// countTokens returns count of every token.
+func countTokens(tokens []string) map[string]int {
+	counts := map[string]int{}
+	for _, t := range tokens {
+		counts[t]++
+	}
+	return counts
+}
+
+func containsString(ss []string, s string) bool {
+	for _, x := range ss {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+package mystem
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/dimuls/classifier/entity"
+)
+
+// output is mystem output of "Большой кот, быстро бежит\nв дом." with
+// input copied to output.
+var output = []string{
+	"{большой=A=им,ед,полн,муж}",
+	" ",
+	"{кот=S,муж,од=им,ед}",
+	", ",
+	"{быстро=ADV=}",
+	" ",
+	"{бежать=V,несов,нп=непрош,ед,изъяв,3-л}",
+	`\s`,
+	"{в=PR=}",
+	" ",
+	"{дом=S,муж,неод=вин,ед|дом=S,муж,неод=им,ед}",
+	".",
+}
+
+func newTestExtractor(lines []string, f Filter) *Extractor {
+	return newExtractor(func(ctx context.Context, text string) ([]string,
+		error) {
+		return lines, nil
+	}, f)
+}
+
+func TestExtractorTokens(t *testing.T) {
+	e := newTestExtractor(output, Filter{})
+
+	tokens, err := e.ExtractTokens("text")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// "в" is a stop word.
+	want := []string{"большой", "кот", "быстро", "бежать", "дом"}
+	if !reflect.DeepEqual(tokens, want) {
+		t.Errorf("ExtractTokens() = %q, want %q", tokens, want)
+	}
+}
+
+func TestExtractorPhrases(t *testing.T) {
+	e := newTestExtractor(output, Filter{})
+
+	phrases, err := e.ExtractPhrases("text")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]string{{"большой", "кот"}, {"быстро", "бежать"}, {"дом"}}
+	if !reflect.DeepEqual(phrases, want) {
+		t.Errorf("ExtractPhrases() = %q, want %q", phrases, want)
+	}
+
+	tokens, phrases2, err := e.ExtractTokensPhrasesContext(
+		context.Background(), "text")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 5 || !reflect.DeepEqual(phrases2, want) {
+		t.Errorf("ExtractTokensPhrasesContext() = %q, %q", tokens, phrases2)
+	}
+}
+
+func TestExtractorFilter(t *testing.T) {
+	e := newTestExtractor(output, Filter{PartsOfSpeech: []string{"S"}})
+
+	words, err := e.ExtractWords("text")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"дом", "кот"}
+	if !reflect.DeepEqual(words, want) {
+		t.Errorf("ExtractWords() = %q, want %q", words, want)
+	}
+
+	// Stop words replace built-in ones.
+	sw := NewStopWords([]entity.StopWord{{Word: "кот"}})
+
+	e = newTestExtractor(output, Filter{StopWords: sw})
+
+	phrases, err := e.ExtractPhrases("text")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantPhrases := [][]string{{"большой"}, {"быстро", "бежать"}, {"в", "дом"}}
+	if !reflect.DeepEqual(phrases, wantPhrases) {
+		t.Errorf("ExtractPhrases() = %q, want %q", phrases, wantPhrases)
+	}
+}
+
+func TestParseOutputLine(t *testing.T) {
+	tests := []struct {
+		line   string
+		word   string
+		isWord bool
+	}{
+		{line: "{кот=S,муж,од=им,ед}", word: "кот", isWord: true},
+		{line: "{classifierdocumentdelimiter??}",
+			word: "classifierdocumentdelimiter", isWord: true},
+		{line: "кот=S,муж,од=им,ед", word: "кот", isWord: true},
+		{line: "Кот", word: "кот", isWord: true},
+		{line: " "},
+		{line: ", "},
+		{line: `\s`},
+		{line: `.\s`},
+		{line: " 42 "},
+	}
+
+	for _, test := range tests {
+		lemmas, isWord := parseOutputLine(test.line)
+		if isWord != test.isWord {
+			t.Errorf("parseOutputLine(%q) word is %v, want %v", test.line,
+				isWord, test.isWord)
+			continue
+		}
+		if isWord && lemmas[0].word != test.word {
+			t.Errorf("parseOutputLine(%q) = %q, want %q", test.line,
+				lemmas[0].word, test.word)
+		}
+	}
+}
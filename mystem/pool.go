@@ -0,0 +1,257 @@
+package mystem
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// delimiter is a word written after every text to mystem process. mystem
+// echoes it back as an unknown word, which marks the end of text output.
+const delimiter = "classifierdocumentdelimiter"
+
+// delimiterRegexp matches delimiter in text. Mystem lowercases lemmas, so
+// delimiter is removed from text in any case.
+var delimiterRegexp = regexp.MustCompile("(?i)" + delimiter)
+
+// Pool is a words extractor which keeps long-running mystem processes and
+// streams texts to them instead of running mystem for every text.
+type Pool struct {
+	*Extractor
+
+	binPath string
+	size    int
+
+	// processes holds idle processes. Nil process means that it crashed
+	// and failed to restart, so it should be started on next use.
+	processes chan *process
+
+	closing   chan struct{}
+	closeOnce sync.Once
+
+	log *logrus.Entry
+}
+
+func NewPool(binPath string, size int) (*Pool, error) {
+	if size < 1 {
+		return nil, errors.New("pool size should be positive")
+	}
+
+	p := &Pool{
+		binPath:   binPath,
+		size:      size,
+		processes: make(chan *process, size),
+		closing:   make(chan struct{}),
+		log:       logrus.WithField("subsystem", "mystem_pool"),
+	}
+
+	p.Extractor = newExtractor(p.analyze, Filter{})
+
+	for i := 0; i < size; i++ {
+		proc, err := startProcess(binPath)
+		if err != nil {
+			close(p.processes)
+			for proc := range p.processes {
+				proc.kill()
+			}
+			return nil, errors.New("failed to start mystem: " + err.Error())
+		}
+		p.processes <- proc
+	}
+
+	return p, nil
+}
+
+// WithFilter returns words extractor which uses pool processes and filters
+// words by f.
+func (p *Pool) WithFilter(f Filter) *Extractor {
+	return newExtractor(p.analyze, f)
+}
+
+// analyze analyzes text by idle process. Process is killed and restarted
+// if ctx is done while it analyzes text.
+func (p *Pool) analyze(ctx context.Context, text string) ([]string, error) {
+	var proc *process
+
+	select {
+	case proc = <-p.processes:
+	case <-p.closing:
+		return nil, errors.New("pool is closed")
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	var err error
+
+	if proc == nil {
+		proc, err = startProcess(p.binPath)
+		if err != nil {
+			p.processes <- nil
+			return nil, errors.New("failed to restart mystem: " + err.Error())
+		}
+	}
+
+	lines, err := proc.analyzeContext(ctx, text)
+	if err != nil {
+		p.log.WithError(err).Warning("mystem process failed, restarting")
+
+		proc.kill()
+
+		newProc, startErr := startProcess(p.binPath)
+		if startErr != nil {
+			p.log.WithError(startErr).Error("failed to restart mystem process")
+		}
+
+		p.processes <- newProc
+
+		return nil, errors.New("mystem process failed: " + err.Error())
+	}
+
+	p.processes <- proc
+
+	return lines, nil
+}
+
+// Close waits for running extractions and stops all mystem processes.
+func (p *Pool) Close() error {
+	p.closeOnce.Do(func() {
+		close(p.closing)
+
+		for i := 0; i < p.size; i++ {
+			proc := <-p.processes
+			if proc == nil {
+				continue
+			}
+			err := proc.stop()
+			if err != nil {
+				p.log.WithError(err).Warning("failed to stop mystem process")
+			}
+		}
+	})
+	return nil
+}
+
+type process struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+}
+
+func startProcess(binPath string) (*process, error) {
+	cmd := newCommand(context.Background(), binPath, mystemArgs...)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, errors.New("failed to get stdin pipe: " + err.Error())
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, errors.New("failed to get stdout pipe: " + err.Error())
+	}
+
+	err = cmd.Start()
+	if err != nil {
+		return nil, err
+	}
+
+	return &process{
+		cmd:    cmd,
+		stdin:  stdin,
+		stdout: bufio.NewReader(stdout),
+	}, nil
+}
+
+// analyzeContext analyzes text and kills process if ctx is done before
+// output is read. Killed process can't be used anymore.
+func (p *process) analyzeContext(ctx context.Context, text string) (
+	[]string, error) {
+
+	done := make(chan struct{})
+	killed := make(chan bool, 1)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			killProcess(p.cmd)
+			killed <- true
+		case <-done:
+			killed <- false
+		}
+	}()
+
+	lines, err := p.analyze(text)
+
+	close(done)
+
+	if <-killed && err == nil {
+		return nil, errors.New("process is killed")
+	}
+
+	return lines, err
+}
+
+// analyze writes text as a single line followed by delimiter line and reads
+// output lines until the delimiter is echoed back.
+func (p *process) analyze(text string) ([]string, error) {
+	text = delimiterRegexp.ReplaceAllLiteralString(text, " ")
+	text = strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\r' {
+			return ' '
+		}
+		return r
+	}, text)
+
+	// Writing in a separate goroutine prevents deadlock when mystem blocks
+	// on full stdout pipe while we block on full stdin pipe.
+	writeErrs := make(chan error, 1)
+	go func() {
+		_, err := io.WriteString(p.stdin, text+"\n"+delimiter+"\n")
+		writeErrs <- err
+	}()
+
+	var lines []string
+
+	for {
+		line, err := p.stdout.ReadString('\n')
+		if err != nil {
+			return nil, errors.New("failed to read output: " + err.Error())
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+
+		lemmas, isWord := parseOutputLine(line)
+		if isWord && lemmas[0].word == delimiter {
+			break
+		}
+
+		lines = append(lines, line)
+	}
+
+	err := <-writeErrs
+	if err != nil {
+		return nil, errors.New("failed to write input: " + err.Error())
+	}
+
+	return lines, nil
+}
+
+func (p *process) stop() error {
+	err := p.stdin.Close()
+	if err != nil {
+		return errors.New("failed to close stdin: " + err.Error())
+	}
+	return p.cmd.Wait()
+}
+
+func (p *process) kill() {
+	killProcess(p.cmd)
+	p.cmd.Wait()
+}
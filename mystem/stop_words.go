@@ -1,5 +1,16 @@
 package mystem
 
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/dimuls/classifier/entity"
+)
+
 var stopWords = map[string]struct{}{
 	"а":              {},
 	"алло":           {},
@@ -997,7 +1008,100 @@ var stopWords = map[string]struct{}{
 	"zero":           {},
 }
 
-func isStopWord(word string) bool {
+// IsStopWord reports whether word is in built-in stop words list.
+func IsStopWord(word string) bool {
 	_, exists := stopWords[word]
 	return exists
 }
+
+// StopWords is a set of stop words.
+type StopWords struct {
+	// words maps word to its language tag.
+	words map[string]string
+
+	hash     string
+	hashOnce sync.Once
+}
+
+func NewStopWords(sws []entity.StopWord) *StopWords {
+	words := make(map[string]string, len(sws))
+	for _, sw := range sws {
+		words[sw.Word] = sw.Language
+	}
+	return &StopWords{words: words}
+}
+
+var (
+	builtinStopWords     *StopWords
+	builtinStopWordsOnce sync.Once
+)
+
+// BuiltinStopWords returns built-in stop words. Words are tagged as russian
+// or english depending on their script.
+func BuiltinStopWords() *StopWords {
+	builtinStopWordsOnce.Do(func() {
+		words := make(map[string]string, len(stopWords))
+		for w := range stopWords {
+			lang := "ru"
+			if strings.IndexFunc(w, func(r rune) bool {
+				return unicode.Is(unicode.Cyrillic, r)
+			}) < 0 {
+				lang = "en"
+			}
+			words[w] = lang
+		}
+		builtinStopWords = &StopWords{words: words}
+	})
+	return builtinStopWords
+}
+
+// Contains reports whether word is a stop word.
+func (sw *StopWords) Contains(word string) bool {
+	_, exists := sw.words[word]
+	return exists
+}
+
+// Merge returns union of stop words.
+func (sw *StopWords) Merge(other *StopWords) *StopWords {
+	words := make(map[string]string, len(sw.words)+len(other.words))
+	for w, lang := range sw.words {
+		words[w] = lang
+	}
+	for w, lang := range other.words {
+		words[w] = lang
+	}
+	return &StopWords{words: words}
+}
+
+// List returns stop words sorted by word.
+func (sw *StopWords) List() []entity.StopWord {
+	list := make([]entity.StopWord, 0, len(sw.words))
+	for w, lang := range sw.words {
+		list = append(list, entity.StopWord{Word: w, Language: lang})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Word < list[j].Word
+	})
+	return list
+}
+
+// Hash returns hex encoded SHA-256 of sorted stop words joined by new
+// lines. Language tags don't affect hash.
+func (sw *StopWords) Hash() string {
+	sw.hashOnce.Do(func() {
+		words := make([]string, 0, len(sw.words))
+		for w := range sw.words {
+			words = append(words, w)
+		}
+		sort.Strings(words)
+
+		hash := sha256.Sum256([]byte(strings.Join(words, "\n")))
+		sw.hash = hex.EncodeToString(hash[:])
+	})
+	return sw.hash
+}
+
+// StopWordsHash returns hash of built-in stop words.
+func StopWordsHash() string {
+	return BuiltinStopWords().Hash()
+}
@@ -2,48 +2,45 @@ package mystem
 
 import (
 	"bufio"
-	"errors"
+	"context"
 	"strings"
 )
 
+// WordsExtractor runs mystem for every extraction. Mystem is killed when
+// context of extraction is done.
 type WordsExtractor struct {
+	*Extractor
+
 	binPath string
 }
 
 func NewWordsExtractor(binPath string) *WordsExtractor {
-	return &WordsExtractor{binPath: binPath}
+	ke := &WordsExtractor{binPath: binPath}
+	ke.Extractor = newExtractor(ke.analyze, Filter{})
+	return ke
 }
 
-func (ke *WordsExtractor) ExtractWords(text string) (
-	[]string, error) {
+// WithFilter returns words extractor which runs mystem the same way and
+// filters words by f.
+func (ke *WordsExtractor) WithFilter(f Filter) *Extractor {
+	return newExtractor(ke.analyze, f)
+}
 
-	if text == "" {
-		return nil, nil
-	}
+func (ke *WordsExtractor) analyze(ctx context.Context, text string) (
+	[]string, error) {
 
-	res, err := ke.runMystem(strings.NewReader(text))
+	res, err := ke.runMystem(ctx, strings.NewReader(text))
 	if err != nil {
-		return nil, errors.New("failed to run mystem: " + err.Error())
+		return nil, err
 	}
 
 	scanner := bufio.NewScanner(res)
 
-	kwsMap := map[string]struct{}{}
+	var lines []string
 
 	for scanner.Scan() {
-		line := scanner.Text()
-		for _, kw := range strings.Split(line, "|") {
-			kw = strings.ToLower(strings.TrimRight(kw, "?"))
-			if !isStopWord(kw) {
-				kwsMap[kw] = struct{}{}
-			}
-		}
-	}
-
-	var kws []string
-	for kw := range kwsMap {
-		kws = append(kws, kw)
+		lines = append(lines, scanner.Text())
 	}
 
-	return kws, nil
+	return lines, nil
 }
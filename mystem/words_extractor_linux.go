@@ -2,22 +2,42 @@ package mystem
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"os/exec"
 	"syscall"
 )
 
-func (ke *WordsExtractor) runMystem(stdin io.Reader) (io.Reader, error) {
+func (ke *WordsExtractor) runMystem(ctx context.Context, stdin io.Reader) (
+	io.Reader, error) {
 
 	stdout := bytes.NewBuffer(nil)
 
-	cmd := exec.Command(ke.binPath, "-n", "-l")
+	cmd := newCommand(ctx, ke.binPath, mystemArgs...)
 	cmd.Stdin = stdin
 	cmd.Stdout = stdout
 
-	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
-
 	err := cmd.Run()
 
 	return stdout, err
 }
+
+// newCommand creates mystem command in its own process group. The whole
+// group is killed when ctx is done.
+func newCommand(ctx context.Context, binPath string,
+	args ...string) *exec.Cmd {
+
+	cmd := exec.CommandContext(ctx, binPath, args...)
+
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return killProcess(cmd)
+	}
+
+	return cmd
+}
+
+// killProcess kills process group of started command.
+func killProcess(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
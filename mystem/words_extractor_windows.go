@@ -2,15 +2,17 @@ package mystem
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"os/exec"
 )
 
-func (ke *WordsExtractor) runMystem(stdin io.Reader) (io.Reader, error) {
+func (ke *WordsExtractor) runMystem(ctx context.Context, stdin io.Reader) (
+	io.Reader, error) {
 
 	stdout := bytes.NewBuffer(nil)
 
-	cmd := exec.Command(ke.binPath, "-n", "-l")
+	cmd := newCommand(ctx, ke.binPath, mystemArgs...)
 	cmd.Stdin = stdin
 	cmd.Stdout = stdout
 
@@ -18,3 +20,15 @@ func (ke *WordsExtractor) runMystem(stdin io.Reader) (io.Reader, error) {
 
 	return stdout, err
 }
+
+// newCommand creates mystem command which is killed when ctx is done.
+func newCommand(ctx context.Context, binPath string,
+	args ...string) *exec.Cmd {
+
+	return exec.CommandContext(ctx, binPath, args...)
+}
+
+// killProcess kills process of started command.
+func killProcess(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
@@ -0,0 +1,62 @@
+package classifier
+
+import (
+	"context"
+	"strings"
+)
+
+// maxNGrams is a maximal supported length of word n-grams.
+const maxNGrams = 3
+
+// PhrasesExtractor is implemented by words extractors which can split text to
+// phrases. Phrase is a sequence of adjacent words which isn't interrupted by
+// stop words or punctuation. Word n-grams are built within phrases only.
+type PhrasesExtractor interface {
+	ExtractPhrases(text string) ([][]string, error)
+}
+
+// ContextTokensPhrasesExtractor is implemented by words extractors which can
+// extract tokens and phrases of text at once, e.g. by single analysis of
+// text. Words are unique tokens.
+type ContextTokensPhrasesExtractor interface {
+	ExtractTokensPhrasesContext(ctx context.Context, text string) ([]string,
+		[][]string, error)
+}
+
+// nGrams returns word n-grams of phrases with length from 2 to maxN. Words
+// of n-gram are joined by space.
+func nGrams(phrases [][]string, maxN int) []string {
+	var grams []string
+
+	for _, phrase := range phrases {
+		for n := 2; n <= maxN; n++ {
+			for i := 0; i+n <= len(phrase); i++ {
+				grams = append(grams, strings.Join(phrase[i:i+n], " "))
+			}
+		}
+	}
+
+	return grams
+}
+
+// isNGram reports whether word is n-gram built by nGrams.
+func isNGram(word string) bool {
+	return strings.Contains(word, " ")
+}
+
+// unique returns unique strings keeping their first occurrence order.
+func unique(ss []string) []string {
+	seen := make(map[string]struct{}, len(ss))
+
+	var res []string
+
+	for _, s := range ss {
+		if _, exists := seen[s]; exists {
+			continue
+		}
+		seen[s] = struct{}{}
+		res = append(res, s)
+	}
+
+	return res
+}
@@ -0,0 +1,122 @@
+package classifier
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dimuls/classifier/entity"
+)
+
+// phrasesWordsExtractor extracts space separated words. Phrases are
+// separated by commas.
+type phrasesWordsExtractor struct{}
+
+func (phrasesWordsExtractor) ExtractWords(text string) ([]string, error) {
+	return unique(strings.Fields(strings.Replace(text, ",", " ", -1))), nil
+}
+
+func (phrasesWordsExtractor) ExtractPhrases(text string) ([][]string,
+	error) {
+
+	var phrases [][]string
+	for _, p := range strings.Split(text, ",") {
+		if words := strings.Fields(p); len(words) > 0 {
+			phrases = append(phrases, words)
+		}
+	}
+	return phrases, nil
+}
+
+func TestNGrams(t *testing.T) {
+	phrases := [][]string{{"a", "b", "c"}, {"d"}, {"a", "b"}}
+
+	grams := nGrams(phrases, 3)
+
+	want := []string{"a b", "b c", "a b c", "a b"}
+	if !reflect.DeepEqual(grams, want) {
+		t.Errorf("nGrams() = %q, want %q", grams, want)
+	}
+
+	if grams := nGrams(phrases, 1); len(grams) != 0 {
+		t.Errorf("nGrams() of single words = %q, want none", grams)
+	}
+
+	if u := unique(grams); !reflect.DeepEqual(u, want[:3]) {
+		t.Errorf("unique() = %q, want %q", u, want[:3])
+	}
+}
+
+func TestTrainNGrams(t *testing.T) {
+	c := NewClassifier(phrasesWordsExtractor{})
+
+	err := c.SetConfig(entity.ModelConfig{NGrams: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Classes differ by word order only.
+	err = c.Train(context.Background(), []entity.Document{
+		{Class: "a", Text: "new york, big apple"},
+		{Class: "b", Text: "york new, apple big"},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertClass(t, c, "new york", "a")
+	assertClass(t, c, "apple big", "b")
+}
+
+func TestNGramsKnownWords(t *testing.T) {
+	c := NewClassifier(phrasesWordsExtractor{})
+
+	err := c.SetConfig(entity.ModelConfig{NGrams: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.Train(context.Background(), []entity.Document{
+		{Class: "a", Text: "new york, big apple"},
+		{Class: "b", Text: "york new, apple big"},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		text         string
+		ratio        float64
+		unknownWords []string
+	}{
+		// "york apple" bigram is unknown, but its words are known.
+		{text: "york apple", ratio: 1},
+		{text: "york tennis", ratio: 0.5, unknownWords: []string{"tennis"}},
+	}
+
+	for _, test := range tests {
+		exp, err := c.Explain(context.Background(), test.text, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if exp.Classification.KnownWordsRatio != test.ratio {
+			t.Errorf("%q known words ratio is %v, want %v", test.text,
+				exp.Classification.KnownWordsRatio, test.ratio)
+		}
+		if !reflect.DeepEqual(exp.UnknownWords, test.unknownWords) {
+			t.Errorf("%q unknown words are %q, want %q", test.text,
+				exp.UnknownWords, test.unknownWords)
+		}
+
+		res, err := c.Classify(context.Background(), test.text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.KnownWordsRatio != test.ratio {
+			t.Errorf("%q classification known words ratio is %v, want %v",
+				test.text, res.KnownWordsRatio, test.ratio)
+		}
+	}
+}
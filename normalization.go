@@ -0,0 +1,87 @@
+package classifier
+
+import (
+	"context"
+
+	"github.com/dimuls/classifier/entity"
+	"github.com/dimuls/classifier/normalizer"
+)
+
+// NormalizingWordsExtractor normalizes text before extracting words by
+// wrapped words extractor.
+type NormalizingWordsExtractor struct {
+	wordsExtractor WordsExtractor
+	normalizer     *normalizer.Normalizer
+}
+
+// NewNormalizingWordsExtractor wraps words extractor by text normalization
+// configured by o.
+func NewNormalizingWordsExtractor(we WordsExtractor,
+	o entity.NormalizationOptions) *NormalizingWordsExtractor {
+
+	return &NormalizingWordsExtractor{
+		wordsExtractor: we,
+		normalizer:     normalizer.New(o),
+	}
+}
+
+func (we *NormalizingWordsExtractor) ExtractWords(text string) ([]string,
+	error) {
+	return we.ExtractWordsContext(context.Background(), text)
+}
+
+func (we *NormalizingWordsExtractor) ExtractWordsContext(
+	ctx context.Context, text string) ([]string, error) {
+
+	return extractWordsContext(ctx, we.wordsExtractor,
+		we.normalizer.Normalize(text))
+}
+
+// ExtractTokens extracts tokens by wrapped words extractor. Words are
+// extracted instead if it can't extract tokens.
+func (we *NormalizingWordsExtractor) ExtractTokens(text string) ([]string,
+	error) {
+	return we.ExtractTokensContext(context.Background(), text)
+}
+
+func (we *NormalizingWordsExtractor) ExtractTokensContext(
+	ctx context.Context, text string) ([]string, error) {
+
+	return extractTokensContext(ctx, we.wordsExtractor,
+		we.normalizer.Normalize(text))
+}
+
+func (we *NormalizingWordsExtractor) ExtractPhrases(text string) (
+	[][]string, error) {
+	return we.ExtractPhrasesContext(context.Background(), text)
+}
+
+func (we *NormalizingWordsExtractor) ExtractPhrasesContext(
+	ctx context.Context, text string) ([][]string, error) {
+
+	return extractPhrasesContext(ctx, we.wordsExtractor,
+		we.normalizer.Normalize(text))
+}
+
+func (we *NormalizingWordsExtractor) ExtractTokensPhrasesContext(
+	ctx context.Context, text string) ([]string, [][]string, error) {
+
+	return extractTokensPhrasesContext(ctx, we.wordsExtractor,
+		we.normalizer.Normalize(text))
+}
+
+// Describe adds normalization steps to description of wrapped words
+// extractor.
+func (we *NormalizingWordsExtractor) Describe() entity.WordsExtractorInfo {
+	info := describeWordsExtractor(we.wordsExtractor)
+
+	settings := make(map[string]string, len(info.Settings)+1)
+	for k, v := range info.Settings {
+		settings[k] = v
+	}
+	settings["normalization"] = we.normalizer.String()
+
+	info.Settings = settings
+
+	return info
+}
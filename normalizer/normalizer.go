@@ -0,0 +1,188 @@
+package normalizer
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/dimuls/classifier/entity"
+)
+
+// Placeholder words replacing URLs, e-mails, dates and numbers. They are
+// plain latin words, so words extractors keep them as is.
+const (
+	URLPlaceholder    = "urlplaceholder"
+	EmailPlaceholder  = "emailplaceholder"
+	DatePlaceholder   = "dateplaceholder"
+	NumberPlaceholder = "numberplaceholder"
+)
+
+var (
+	htmlHiddenRegexp = regexp.MustCompile(
+		`(?is)<(script|style)[^>]*>.*?</(script|style)>|<!--.*?-->`)
+	htmlTagRegexp = regexp.MustCompile(`<[a-zA-Z/!][^>]*>`)
+
+	urlRegexp = regexp.MustCompile(
+		`(?i)\b(?:https?://|ftp://|www\.)[^\s<>"]+[^\s<>".,;:!?)\]]`)
+	emailRegexp = regexp.MustCompile(
+		`(?i)\b[a-z0-9._%+-]+@[a-z0-9-]+(?:\.[a-z0-9-]+)*\.[a-z]{2,}\b`)
+	dateRegexp = regexp.MustCompile(
+		`\b\d{4}-\d{1,2}-\d{1,2}\b|\b\d{1,2}[./]\d{1,2}[./]\d{2,4}\b|` +
+			`\b\d{1,2}\s+(?i:января|февраля|марта|апреля|мая|июня|июля|` +
+			`августа|сентября|октября|ноября|декабря)(?:\s+\d{4})?`)
+	numberRegexp = regexp.MustCompile(`\d+(?:[.,]\d+)*`)
+)
+
+// Normalizer normalizes text before words extraction.
+type Normalizer struct {
+	options entity.NormalizationOptions
+	steps   []func(text string) string
+}
+
+// New creates normalizer which applies steps enabled in o.
+func New(o entity.NormalizationOptions) *Normalizer {
+	n := &Normalizer{options: o}
+
+	if o.StripHTML {
+		n.steps = append(n.steps, StripHTML)
+	}
+	if o.NormalizeUnicode {
+		n.steps = append(n.steps, NormalizeUnicode)
+	}
+	if o.ReplaceURLs {
+		n.steps = append(n.steps, replacer(urlRegexp, URLPlaceholder))
+	}
+	if o.ReplaceEmails {
+		n.steps = append(n.steps, replacer(emailRegexp, EmailPlaceholder))
+	}
+	if o.ReplaceDates {
+		n.steps = append(n.steps, replacer(dateRegexp, DatePlaceholder))
+	}
+	if o.ReplaceNumbers {
+		n.steps = append(n.steps, replacer(numberRegexp, NumberPlaceholder))
+	}
+	if o.FoldYo {
+		n.steps = append(n.steps, FoldYo)
+	}
+	if o.MaxWordLength > 0 {
+		n.steps = append(n.steps, func(text string) string {
+			return dropLongWords(text, o.MaxWordLength)
+		})
+	}
+	if o.MaxTextLength > 0 {
+		n.steps = append(n.steps, func(text string) string {
+			return truncate(text, o.MaxTextLength)
+		})
+	}
+
+	return n
+}
+
+// Normalize returns normalized text.
+func (n *Normalizer) Normalize(text string) string {
+	for _, step := range n.steps {
+		text = step(text)
+	}
+	return text
+}
+
+// String returns comma separated list of enabled steps. It's used in words
+// extractor description.
+func (n *Normalizer) String() string {
+	var steps []string
+
+	for _, s := range []struct {
+		name    string
+		enabled bool
+	}{
+		{"html", n.options.StripHTML},
+		{"unicode", n.options.NormalizeUnicode},
+		{"urls", n.options.ReplaceURLs},
+		{"emails", n.options.ReplaceEmails},
+		{"dates", n.options.ReplaceDates},
+		{"numbers", n.options.ReplaceNumbers},
+		{"yo", n.options.FoldYo},
+	} {
+		if s.enabled {
+			steps = append(steps, s.name)
+		}
+	}
+
+	if n.options.MaxWordLength > 0 {
+		steps = append(steps,
+			"max_word_length="+strconv.Itoa(n.options.MaxWordLength))
+	}
+	if n.options.MaxTextLength > 0 {
+		steps = append(steps,
+			"max_text_length="+strconv.Itoa(n.options.MaxTextLength))
+	}
+
+	return strings.Join(steps, ",")
+}
+
+// StripHTML removes HTML tags, scripts, styles and comments and unescapes
+// HTML entities. Tags are replaced by spaces, so words of adjacent
+// elements aren't glued.
+func StripHTML(text string) string {
+	text = htmlHiddenRegexp.ReplaceAllString(text, " ")
+	text = htmlTagRegexp.ReplaceAllString(text, " ")
+	return UnescapeHTML(text)
+}
+
+// UnescapeHTML replaces HTML entities with characters they stand for.
+func UnescapeHTML(text string) string {
+	return html.UnescapeString(text)
+}
+
+// NormalizeUnicode applies NFKC normalization and removes format
+// characters.
+func NormalizeUnicode(text string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.Is(unicode.Cf, r) {
+			return -1
+		}
+		return r
+	}, norm.NFKC.String(text))
+}
+
+// FoldYo replaces "ё" by "е" keeping case.
+func FoldYo(text string) string {
+	return strings.NewReplacer("ё", "е", "Ё", "Е").Replace(text)
+}
+
+func replacer(re *regexp.Regexp, placeholder string) func(string) string {
+	placeholder = " " + placeholder + " "
+	return func(text string) string {
+		return re.ReplaceAllLiteralString(text, placeholder)
+	}
+}
+
+// dropLongWords removes space separated words longer than max runes.
+func dropLongWords(text string, max int) string {
+	words := strings.Fields(text)
+
+	kept := words[:0]
+	for _, w := range words {
+		if len([]rune(w)) <= max {
+			kept = append(kept, w)
+		}
+	}
+
+	return strings.Join(kept, " ")
+}
+
+// truncate returns the first max runes of text.
+func truncate(text string, max int) string {
+	i := 0
+	for j := range text {
+		if i == max {
+			return text[:j]
+		}
+		i++
+	}
+	return text
+}
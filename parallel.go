@@ -0,0 +1,151 @@
+package classifier
+
+import (
+	"context"
+	"sync"
+)
+
+// parallel calls f for every index in [0, n) using at most workers
+// goroutines and waits for all calls to finish.
+func parallel(n int, workers int, f func(i int)) {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > n {
+		workers = n
+	}
+
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				f(i)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+
+	close(indexes)
+
+	wg.Wait()
+}
+
+// parallelOrdered calls extract for every index in [0, n) using at most
+// workers goroutines and calls consume with extracted words in order of
+// indexes. Workers run ahead of consume by a few indexes only. The first
+// error of extract or consume stops all workers and is returned. Context
+// error is returned if ctx is done.
+func parallelOrdered(ctx context.Context, n int, workers int,
+	extract func(ctx context.Context, i int) ([]string, error),
+	consume func(i int, words []string) error) error {
+
+	if n == 0 {
+		return nil
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > n {
+		workers = n
+	}
+
+	workersCtx, cancel := context.WithCancel(ctx)
+
+	var (
+		firstErr error
+		errOnce  sync.Once
+	)
+
+	fail := func(err error) {
+		errOnce.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	type result struct {
+		words []string
+		err   error
+	}
+
+	results := make([]chan result, n)
+	for i := range results {
+		results[i] = make(chan result, 1)
+	}
+
+	// window limits number of extracted but not consumed results.
+	window := make(chan struct{}, 2*workers)
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(indexes)
+		for i := 0; i < n; i++ {
+			select {
+			case window <- struct{}{}:
+			case <-workersCtx.Done():
+				return
+			}
+			select {
+			case indexes <- i:
+			case <-workersCtx.Done():
+				return
+			}
+		}
+	}()
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				words, err := extract(workersCtx, i)
+				if err != nil {
+					fail(err)
+				}
+				results[i] <- result{words: words, err: err}
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		var r result
+
+		select {
+		case r = <-results[i]:
+		case <-workersCtx.Done():
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if r.err != nil || workersCtx.Err() != nil {
+			return firstErr
+		}
+
+		<-window
+
+		err := consume(i, r.words)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,189 @@
+package classifier
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParallelOrdered(t *testing.T) {
+	const n = 100
+
+	var consumed []int
+
+	err := parallelOrdered(context.Background(), n, 8,
+		func(ctx context.Context, i int) ([]string, error) {
+			time.Sleep(time.Duration(rand.Intn(1000)) * time.Microsecond)
+			return []string{strconv.Itoa(i)}, nil
+		},
+		func(i int, words []string) error {
+			if len(words) != 1 || words[0] != strconv.Itoa(i) {
+				t.Errorf("consume(%d) got words %q", i, words)
+			}
+			consumed = append(consumed, i)
+			return nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(consumed) != n {
+		t.Fatalf("consumed %d results, want %d", len(consumed), n)
+	}
+	for i, c := range consumed {
+		if c != i {
+			t.Fatalf("result %d is consumed at position %d", c, i)
+		}
+	}
+}
+
+func TestParallelOrderedWindow(t *testing.T) {
+	const (
+		n       = 50
+		workers = 4
+	)
+
+	var extracted, maxAhead int64
+
+	err := parallelOrdered(context.Background(), n, workers,
+		func(ctx context.Context, i int) ([]string, error) {
+			atomic.AddInt64(&extracted, 1)
+			return nil, nil
+		},
+		func(i int, words []string) error {
+			time.Sleep(time.Millisecond)
+			ahead := atomic.LoadInt64(&extracted) - int64(i) - 1
+			if ahead > maxAhead {
+				maxAhead = ahead
+			}
+			return nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if maxAhead > 2*workers {
+		t.Errorf("workers run ahead by %d results, want at most %d",
+			maxAhead, 2*workers)
+	}
+}
+
+func TestParallelOrderedFirstError(t *testing.T) {
+	const (
+		n      = 100
+		failed = 10
+	)
+
+	extractErr := errors.New("extract failed")
+
+	var consumed int
+
+	err := parallelOrdered(context.Background(), n, 4,
+		func(ctx context.Context, i int) ([]string, error) {
+			if i == failed {
+				return nil, extractErr
+			}
+			if i > failed {
+				// Later documents wait for cancellation.
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}
+			return nil, nil
+		},
+		func(i int, words []string) error {
+			consumed++
+			return nil
+		})
+	if err != extractErr {
+		t.Fatalf("got error %v, want %v", err, extractErr)
+	}
+
+	// Results extracted before failure may be not consumed, but results
+	// after it never are.
+	if consumed > failed {
+		t.Errorf("consumed %d results, want at most %d", consumed, failed)
+	}
+}
+
+func TestParallelOrderedConsumeError(t *testing.T) {
+	consumeErr := errors.New("consume failed")
+
+	var calls int64
+
+	err := parallelOrdered(context.Background(), 100, 4,
+		func(ctx context.Context, i int) ([]string, error) {
+			atomic.AddInt64(&calls, 1)
+			return nil, nil
+		},
+		func(i int, words []string) error {
+			if i == 3 {
+				return consumeErr
+			}
+			return nil
+		})
+	if err != consumeErr {
+		t.Fatalf("got error %v, want %v", err, consumeErr)
+	}
+
+	if calls == 100 {
+		t.Error("extraction isn't stopped by consume error")
+	}
+}
+
+func TestParallelOrderedCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var (
+		running int64
+		started sync.WaitGroup
+	)
+
+	started.Add(4)
+
+	go func() {
+		started.Wait()
+		cancel()
+	}()
+
+	err := parallelOrdered(ctx, 100, 4,
+		func(ctx context.Context, i int) ([]string, error) {
+			atomic.AddInt64(&running, 1)
+			defer atomic.AddInt64(&running, -1)
+			if i < 4 {
+				started.Done()
+			}
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+		func(i int, words []string) error {
+			t.Errorf("consume(%d) is called", i)
+			return nil
+		})
+	if err != context.Canceled {
+		t.Fatalf("got error %v, want %v", err, context.Canceled)
+	}
+
+	if r := atomic.LoadInt64(&running); r != 0 {
+		t.Errorf("%d extractions are running after return", r)
+	}
+}
+
+func TestParallelOrderedEmpty(t *testing.T) {
+	err := parallelOrdered(context.Background(), 0, 4,
+		func(ctx context.Context, i int) ([]string, error) {
+			t.Errorf("extract(%d) is called", i)
+			return nil, nil
+		},
+		func(i int, words []string) error {
+			t.Errorf("consume(%d) is called", i)
+			return nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,336 @@
+package classifier
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	"github.com/jbrukh/bayesian"
+
+	"github.com/dimuls/classifier/entity"
+)
+
+const (
+	// metadataSuffix is appended to model file path to get its metadata file
+	// path.
+	metadataSuffix = ".meta"
+
+	// previousSuffix is appended to model file path to get path of model
+	// file which is replaced by save.
+	previousSuffix = ".prev"
+)
+
+// encodedClassifier is classifier file content with its metadata.
+type encodedClassifier struct {
+	data []byte
+	meta entity.ModelMetadata
+}
+
+// Save atomically saves classifier to file with metadata file next to it.
+// Both files are written to temporary files, synced and renamed into place.
+// Replaced model file is kept as previous file until metadata file is
+// replaced too, so interrupted save can be recovered by Load. Untrained
+// classifier isn't saved.
+func (c *Classifier) Save(path string) error {
+	ec, err := c.encode()
+	if err != nil {
+		return err
+	}
+
+	if ec == nil {
+		return nil
+	}
+
+	return c.saveEncoded(path, ec)
+}
+
+// encode encodes classifier with its metadata. Classifier is locked while
+// it's encoded only, so files are written without blocking training and
+// learning. It returns nil if classifier isn't trained.
+func (c *Classifier) encode() (*encodedClassifier, error) {
+	c.classifierMutex.RLock()
+	defer c.classifierMutex.RUnlock()
+
+	if c.classifier == nil {
+		return nil, nil
+	}
+
+	var buf bytes.Buffer
+
+	err := c.classifier.WriteTo(&buf)
+	if err != nil {
+		return nil, errors.New("failed to encode classifier: " + err.Error())
+	}
+
+	meta := entity.ModelMetadata{
+		Checksum:  checksum(buf.Bytes()),
+		Created:   time.Now(),
+		Documents: c.classifier.Learned(),
+		TfIdf:     c.classifier.IsTfIdf(),
+		NGrams:    c.extraction.nGrams,
+
+		Extraction:     c.extraction.options,
+		WordsExtractor: c.trainedWith,
+	}
+
+	for _, class := range c.classifier.Classes {
+		meta.Classes = append(meta.Classes, string(class))
+	}
+
+	return &encodedClassifier{data: buf.Bytes(), meta: meta}, nil
+}
+
+// saveEncoded saves encoded classifier to file and removes previous file.
+func (c *Classifier) saveEncoded(path string, ec *encodedClassifier) error {
+	err := ec.write(path)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path + previousSuffix)
+	if err != nil && !os.IsNotExist(err) {
+		c.log.WithError(err).Warning(
+			"failed to remove previous classifier file")
+	}
+
+	return nil
+}
+
+// write writes classifier file and its metadata file. Replaced classifier
+// file is kept as previous file.
+func (ec *encodedClassifier) write(path string) error {
+	err := keepPrevious(path)
+	if err != nil {
+		return errors.New("failed to keep previous classifier file: " +
+			err.Error())
+	}
+
+	err = writeFileAtomic(path, func(w io.Writer) error {
+		_, err := w.Write(ec.data)
+		return err
+	})
+	if err != nil {
+		return errors.New("failed to write classifier file: " + err.Error())
+	}
+
+	err = writeFileAtomic(path+metadataSuffix, func(w io.Writer) error {
+		return json.NewEncoder(w).Encode(ec.meta)
+	})
+	if err != nil {
+		return errors.New("failed to write metadata file: " + err.Error())
+	}
+
+	return nil
+}
+
+// keepPrevious links model file to previous file. Model file is copied if it
+// can't be linked.
+func keepPrevious(path string) error {
+	prevPath := path + previousSuffix
+
+	err := os.Remove(prevPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	err = os.Link(path, prevPath)
+	if err == nil || os.IsNotExist(err) {
+		return nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	return writeFileAtomic(prevPath, func(w io.Writer) error {
+		_, err := io.Copy(w, f)
+		return err
+	})
+}
+
+// Load loads classifier from file and verifies it against metadata file
+// before swapping it in. Files saved without metadata are loaded
+// unverified. If save of file was interrupted before metadata file was
+// replaced, previous file is restored and loaded.
+func (c *Classifier) Load(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.New("failed to read classifier file: " + err.Error())
+	}
+
+	meta, err := ReadMetadata(path)
+	switch {
+	case os.IsNotExist(err):
+		c.log.WithField("path", path).
+			Warning("classifier metadata file not found, loading unverified")
+	case err != nil:
+		return err
+	case checksum(data) != meta.Checksum:
+		data, err = restorePrevious(path, meta.Checksum)
+		if err != nil {
+			return err
+		}
+		c.log.WithField("path", path).Warning(
+			"classifier file save was interrupted, previous file is restored")
+	}
+
+	classifier, err := bayesian.NewClassifierFromReader(bytes.NewReader(data))
+	if err != nil {
+		return errors.New("failed to decode classifier: " + err.Error())
+	}
+
+	e, err := c.restoreExtraction(meta.Extraction, classifier.IsTfIdf(),
+		meta.NGrams)
+	if err != nil {
+		return err
+	}
+
+	// Classifier saved without metadata is assumed to be trained with
+	// current words extractor.
+	trainedWith := meta.WordsExtractor
+	if trainedWith.Name == "" {
+		trainedWith = describeWordsExtractor(e.wordsExtractor)
+	}
+
+	if !sameWordsExtractor(trainedWith,
+		describeWordsExtractor(e.wordsExtractor)) {
+		c.log.WithField("path", path).Warning(
+			"classifier is trained with different words extractor")
+	}
+
+	c.classifierMutex.Lock()
+	c.classifier = classifier
+	c.extraction = e
+	c.trainedWith = trainedWith
+	c.knownWordsCache = nil
+	c.version = c.newVersion()
+	c.classifierMutex.Unlock()
+
+	return nil
+}
+
+// restorePrevious renames previous model file to path if it matches checksum
+// and returns its content.
+func restorePrevious(path string, sum string) ([]byte, error) {
+	prevPath := path + previousSuffix
+
+	data, err := ioutil.ReadFile(prevPath)
+	if os.IsNotExist(err) {
+		return nil, errors.New("classifier file checksum mismatch")
+	}
+	if err != nil {
+		return nil, errors.New("failed to read previous classifier file: " +
+			err.Error())
+	}
+
+	if checksum(data) != sum {
+		return nil, errors.New("classifier file checksum mismatch")
+	}
+
+	err = os.Rename(prevPath, path)
+	if err != nil {
+		return nil, errors.New("failed to restore previous classifier file: " +
+			err.Error())
+	}
+
+	syncDir(filepath.Dir(path))
+
+	return data, nil
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// sameWordsExtractor reports whether words extractors descriptions match.
+// Unknown words extractors match any other.
+func sameWordsExtractor(a, b entity.WordsExtractorInfo) bool {
+	if a.Name == "" || b.Name == "" {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// ReadMetadata reads metadata of model file. Returned error satisfies
+// os.IsNotExist if metadata file doesn't exist.
+func ReadMetadata(path string) (entity.ModelMetadata, error) {
+	var meta entity.ModelMetadata
+
+	f, err := os.Open(path + metadataSuffix)
+	if err != nil {
+		return meta, err
+	}
+
+	defer f.Close()
+
+	err = json.NewDecoder(f).Decode(&meta)
+	if err != nil {
+		return meta, errors.New("failed to decode metadata: " + err.Error())
+	}
+
+	return meta, nil
+}
+
+// writeFileAtomic writes file using write function to temporary file in the
+// same directory, syncs it and renames it to path.
+func writeFileAtomic(path string, write func(w io.Writer) error) error {
+	dir := filepath.Dir(path)
+
+	f, err := ioutil.TempFile(dir, filepath.Base(path)+".tmp")
+	if err != nil {
+		return errors.New("failed to create temporary file: " + err.Error())
+	}
+
+	tmpPath := f.Name()
+
+	err = write(f)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	err = os.Chmod(tmpPath, 0644)
+	if err != nil {
+		os.Remove(tmpPath)
+		return errors.New("failed to chmod temporary file: " + err.Error())
+	}
+
+	err = os.Rename(tmpPath, path)
+	if err != nil {
+		os.Remove(tmpPath)
+		return errors.New("failed to rename temporary file: " + err.Error())
+	}
+
+	syncDir(dir)
+
+	return nil
+}
+
+// syncDir syncs directory to persist renames in it. It's best effort since
+// directories can't be synced on some platforms.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	d.Sync()
+	d.Close()
+}
@@ -0,0 +1,246 @@
+package classifier
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/dimuls/classifier/entity"
+)
+
+// fieldsWordsExtractor extracts space separated words.
+type fieldsWordsExtractor struct{}
+
+func (fieldsWordsExtractor) ExtractWords(text string) ([]string, error) {
+	return strings.Fields(text), nil
+}
+
+var testDocs = []entity.Document{
+	{Class: "politics", Text: "election vote party"},
+	{Class: "sport", Text: "football match goal"},
+	{Class: "politics", Text: "parliament vote law"},
+	{Class: "sport", Text: "hockey match team"},
+}
+
+func newTrainedClassifier(t *testing.T,
+	docs []entity.Document) *Classifier {
+
+	c := NewClassifier(fieldsWordsExtractor{})
+
+	err := c.Train(context.Background(), docs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
+
+func newTempDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "classifier")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+func assertClass(t *testing.T, c *Classifier, text string, class string) {
+	t.Helper()
+
+	res, err := c.Classify(context.Background(), text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Class != class {
+		t.Errorf("%q is classified as %q, want %q", text, res.Class, class)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	dir, remove := newTempDir(t)
+	defer remove()
+
+	path := filepath.Join(dir, "model")
+
+	err := newTrainedClassifier(t, testDocs).Save(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	meta, err := ReadMetadata(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Documents != len(testDocs) {
+		t.Errorf("got %d documents in metadata, want %d", meta.Documents,
+			len(testDocs))
+	}
+	sort.Strings(meta.Classes)
+	if strings.Join(meta.Classes, ",") != "politics,sport" {
+		t.Errorf("got classes %q in metadata", meta.Classes)
+	}
+
+	c := NewClassifier(fieldsWordsExtractor{})
+
+	err = c.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertClass(t, c, "football goal", "sport")
+	assertClass(t, c, "election law", "politics")
+
+	// Model file without metadata is loaded unverified.
+	err = os.Remove(path + metadataSuffix)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = NewClassifier(fieldsWordsExtractor{}).Load(path)
+	if err != nil {
+		t.Errorf("failed to load model file without metadata: %v", err)
+	}
+}
+
+func TestSaveUntrained(t *testing.T) {
+	dir, remove := newTempDir(t)
+	defer remove()
+
+	path := filepath.Join(dir, "model")
+
+	err := NewClassifier(fieldsWordsExtractor{}).Save(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("untrained classifier is saved")
+	}
+}
+
+func TestLoadChecksumMismatch(t *testing.T) {
+	dir, remove := newTempDir(t)
+	defer remove()
+
+	path := filepath.Join(dir, "model")
+	otherPath := filepath.Join(dir, "other")
+
+	err := newTrainedClassifier(t, testDocs).Save(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = newTrainedClassifier(t, testDocs[1:]).Save(otherPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Model file is replaced, but its metadata isn't.
+	err = os.Rename(otherPath, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewClassifier(fieldsWordsExtractor{})
+
+	err = c.Load(path)
+	if err == nil {
+		t.Fatal("model file with wrong checksum is loaded")
+	}
+	if c.Trained() {
+		t.Error("model file with wrong checksum is swapped in")
+	}
+}
+
+func TestLoadRestoresPrevious(t *testing.T) {
+	dir, remove := newTempDir(t)
+	defer remove()
+
+	path := filepath.Join(dir, "model")
+	otherPath := filepath.Join(dir, "other")
+
+	err := newTrainedClassifier(t, testDocs).Save(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = newTrainedClassifier(t, testDocs[1:]).Save(otherPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Save is interrupted after model file is replaced, but before its
+	// metadata is.
+	err = keepPrevious(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.Rename(otherPath, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewClassifier(fieldsWordsExtractor{})
+
+	err = c.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertClass(t, c, "election vote", "politics")
+
+	restored, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(restored) != string(saved) {
+		t.Error("previous model file isn't restored")
+	}
+
+	if _, err := os.Stat(path + previousSuffix); !os.IsNotExist(err) {
+		t.Error("previous model file is kept after restore")
+	}
+}
+
+func TestLoadPreviousMismatch(t *testing.T) {
+	dir, remove := newTempDir(t)
+	defer remove()
+
+	path := filepath.Join(dir, "model")
+	otherPath := filepath.Join(dir, "other")
+
+	err := newTrainedClassifier(t, testDocs).Save(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = newTrainedClassifier(t, testDocs[1:]).Save(otherPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Previous model file doesn't match metadata too.
+	err = os.Link(otherPath, path+previousSuffix)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.Rename(otherPath, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = NewClassifier(fieldsWordsExtractor{}).Load(path)
+	if err == nil {
+		t.Error("model file with wrong checksum is loaded")
+	}
+}
@@ -0,0 +1,295 @@
+package classifier
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/dimuls/classifier/entity"
+)
+
+// DefaultModelName is a name of model which always exists in registry.
+const DefaultModelName = "default"
+
+// modelFileExt is an extension of model files in registry data directory.
+const modelFileExt = ".model"
+
+var modelNameRegexp = regexp.MustCompile(`^[a-z0-9_-]{1,64}$`)
+
+// reservedModelName is used in routes of default model versions.
+const reservedModelName = "versions"
+
+var (
+	ErrModelNotFound = errors.New("model not found")
+	ErrModelExists   = errors.New("model already exists")
+)
+
+// Registry holds named models. Every model is persisted to its own file in
+// data directory. Model is persisted only after it's trained, so untrained
+// model doesn't survive restart.
+type Registry struct {
+	dataDir           string
+	newWordsExtractor WordsExtractorFactory
+	keepSnapshots     int
+	modelConfig       entity.ModelConfig
+	workers           int
+
+	models      map[string]*Model
+	modelsMutex sync.RWMutex
+
+	// deleting holds names of models which files are being removed. Models
+	// with these names can't be created until removal is finished.
+	deleting map[string]struct{}
+
+	log *logrus.Entry
+}
+
+// RegistryConfig is a configuration of models registry.
+type RegistryConfig struct {
+	// DataDir is a directory where models are persisted.
+	DataDir string
+
+	// DefaultModelFilePath is a file path of default model. Default model
+	// is persisted to data dir if it's empty.
+	DefaultModelFilePath string
+
+	// KeepSnapshots is a number of the last snapshots every model keeps.
+	KeepSnapshots int
+
+	// ModelConfig is a configuration of models which don't have their own.
+	ModelConfig entity.ModelConfig
+
+	// Workers is a number of goroutines every model extracts words with.
+	// Zero means number of CPUs.
+	Workers int
+}
+
+// NewRegistry creates registry and loads models from data directory. Words
+// extractors of models are created by f according to their configs.
+func NewRegistry(cfg RegistryConfig, f WordsExtractorFactory) (*Registry,
+	error) {
+
+	err := validateConfig(cfg.ModelConfig)
+	if err != nil {
+		return nil, errors.New("invalid model config: " + err.Error())
+	}
+
+	r := &Registry{
+		dataDir:           cfg.DataDir,
+		newWordsExtractor: f,
+		keepSnapshots:     cfg.KeepSnapshots,
+		modelConfig:       cfg.ModelConfig,
+		workers:           cfg.Workers,
+		models:            map[string]*Model{},
+		deleting:          map[string]struct{}{},
+		log:               logrus.WithField("subsystem", "registry"),
+	}
+
+	defaultModelFilePath := cfg.DefaultModelFilePath
+	if defaultModelFilePath == "" {
+		defaultModelFilePath = r.modelFilePath(DefaultModelName)
+	}
+
+	r.models[DefaultModelName] = r.newModel(DefaultModelName,
+		defaultModelFilePath)
+
+	if r.dataDir != "" {
+		err := os.MkdirAll(r.dataDir, 0755)
+		if err != nil {
+			return nil, errors.New("failed to create data dir: " +
+				err.Error())
+		}
+	}
+
+	files, err := ioutil.ReadDir(filepath.Join(r.dataDir, "."))
+	if err != nil {
+		return nil, errors.New("failed to read data dir: " + err.Error())
+	}
+
+	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) != modelFileExt {
+			continue
+		}
+		name := strings.TrimSuffix(f.Name(), modelFileExt)
+		if !modelNameRegexp.MatchString(name) {
+			continue
+		}
+		if _, exists := r.models[name]; exists {
+			continue
+		}
+		r.models[name] = r.newModel(name, r.modelFilePath(name))
+	}
+
+	for _, m := range r.models {
+		err := m.LoadConfig()
+		if err != nil {
+			return nil, errors.New("failed to load model " + m.name +
+				" config: " + err.Error())
+		}
+
+		fileStat, err := os.Stat(m.filePath)
+		if os.IsNotExist(err) || (err == nil && fileStat.IsDir()) {
+			continue
+		}
+		err = m.Load()
+		if err != nil {
+			return nil, errors.New("failed to load model " + m.name +
+				": " + err.Error())
+		}
+	}
+
+	return r, nil
+}
+
+func (r *Registry) newModel(name string, filePath string) *Model {
+	m := newModel(name, filePath, r.newWordsExtractor, r.keepSnapshots,
+		r.modelConfig)
+	m.SetWorkers(r.workers)
+	return m
+}
+
+func (r *Registry) modelFilePath(name string) string {
+	return filepath.Join(r.dataDir, name+modelFileExt)
+}
+
+func (r *Registry) Model(name string) (*Model, error) {
+	r.modelsMutex.RLock()
+	defer r.modelsMutex.RUnlock()
+
+	m, exists := r.models[name]
+	if !exists {
+		return nil, ErrModelNotFound
+	}
+
+	return m, nil
+}
+
+func (r *Registry) Create(name string) (*Model, error) {
+	if !modelNameRegexp.MatchString(name) {
+		return nil, errors.New("invalid model name, it should match " +
+			modelNameRegexp.String())
+	}
+
+	if name == reservedModelName {
+		return nil, errors.New("model name " + name + " is reserved")
+	}
+
+	r.modelsMutex.Lock()
+	defer r.modelsMutex.Unlock()
+
+	if _, exists := r.models[name]; exists {
+		return nil, ErrModelExists
+	}
+
+	if _, deleting := r.deleting[name]; deleting {
+		return nil, errors.New("model is being deleted")
+	}
+
+	m := r.newModel(name, r.modelFilePath(name))
+
+	r.models[name] = m
+
+	return m, nil
+}
+
+// Delete removes model and its files. Training jobs of model are cancelled.
+// Default model can't be deleted.
+func (r *Registry) Delete(name string) error {
+	if name == DefaultModelName {
+		return errors.New("default model can't be deleted")
+	}
+
+	r.modelsMutex.Lock()
+
+	m, exists := r.models[name]
+	if !exists {
+		r.modelsMutex.Unlock()
+		return ErrModelNotFound
+	}
+
+	delete(r.models, name)
+	r.deleting[name] = struct{}{}
+
+	r.modelsMutex.Unlock()
+
+	defer func() {
+		r.modelsMutex.Lock()
+		delete(r.deleting, name)
+		r.modelsMutex.Unlock()
+	}()
+
+	// Jobs are waited outside of registry lock, since saving trained model
+	// can take a while. Model isn't saved after it's marked deleted, so its
+	// files aren't recreated.
+	m.markDeleted()
+	m.jobs.CancelAll()
+	m.Wait()
+
+	for _, path := range []string{m.filePath,
+		m.filePath + metadataSuffix, m.filePath + previousSuffix,
+		m.filePath + feedbackLogSuffix,
+		m.filePath + configSuffix} {
+		err := os.Remove(path)
+		if err != nil && !os.IsNotExist(err) {
+			return errors.New("failed to remove model file: " + err.Error())
+		}
+	}
+
+	err := m.snapshots.Remove()
+	if err != nil {
+		return errors.New("failed to remove model snapshots: " + err.Error())
+	}
+
+	return nil
+}
+
+// Names returns sorted names of models.
+func (r *Registry) Names() []string {
+	r.modelsMutex.RLock()
+	defer r.modelsMutex.RUnlock()
+
+	names := make([]string, 0, len(r.models))
+	for name := range r.models {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Wait waits for training jobs of all models to finish.
+func (r *Registry) Wait() {
+	r.modelsMutex.RLock()
+	defer r.modelsMutex.RUnlock()
+
+	for _, m := range r.models {
+		m.Wait()
+	}
+}
+
+// Save saves all changed models to their files. It tries to save every model
+// and returns the first error.
+func (r *Registry) Save() error {
+	r.modelsMutex.RLock()
+	defer r.modelsMutex.RUnlock()
+
+	var firstErr error
+
+	for _, m := range r.models {
+		err := m.SaveChanged()
+		if err != nil && firstErr == nil {
+			firstErr = errors.New("failed to save model " + m.name + ": " +
+				err.Error())
+		}
+	}
+
+	return firstErr
+}
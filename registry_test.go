@@ -0,0 +1,67 @@
+package classifier
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/dimuls/classifier/entity"
+)
+
+func TestRegistryDeleteTraining(t *testing.T) {
+	dir, remove := newTempDir(t)
+	defer remove()
+
+	r, err := NewRegistry(RegistryConfig{
+		DataDir:       dir,
+		KeepSnapshots: 2,
+	}, StaticWordsExtractorFactory(fieldsWordsExtractor{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := r.Create("news")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first job is running or done, the rest are queued.
+	var jobs []entity.TrainingJob
+	for i := 0; i < 3; i++ {
+		jobs = append(jobs, m.StartTraining(testDocs))
+	}
+
+	err = r.Delete("news")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, j := range jobs {
+		j, _ := m.TrainingJob(j.ID)
+		if !j.Done() {
+			t.Errorf("job %s is %s after model deletion", j.ID, j.State)
+		}
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "news"+modelFileExt+"*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 0 {
+		t.Errorf("model files %q aren't removed", files)
+	}
+
+	if _, err := r.Model("news"); err != ErrModelNotFound {
+		t.Errorf("got error %v, want %v", err, ErrModelNotFound)
+	}
+
+	// Deleted model isn't saved anymore.
+	err = m.Save()
+	if err != errModelDeleted {
+		t.Errorf("got save error %v, want %v", err, errModelDeleted)
+	}
+
+	_, err = r.Create("news")
+	if err != nil {
+		t.Errorf("failed to create deleted model again: %v", err)
+	}
+}
@@ -1,42 +1,352 @@
 package classifier
 
 import (
+	"errors"
+	"io"
 	"os"
+	"path/filepath"
+	"sync"
+	"time"
 
+	"github.com/sirupsen/logrus"
+
+	"github.com/dimuls/classifier/entity"
 	"github.com/dimuls/classifier/mystem"
+	"github.com/dimuls/classifier/stemmer"
 	"github.com/dimuls/classifier/web"
 )
 
+// Words extractors which can be selected in Config.
+const (
+	MystemWordsExtractor  = "mystem"
+	StemmerWordsExtractor = "stemmer"
+)
+
+type Config struct {
+	// WordsExtractor is one of MystemWordsExtractor (default) and
+	// StemmerWordsExtractor.
+	WordsExtractor string
+
+	MystemBinPath string
+
+	// MystemPoolSize is a number of long-running mystem processes. Zero
+	// means that mystem runs for every extraction.
+	MystemPoolSize int
+
+	// ExtractionWorkers is a number of goroutines extracting words of
+	// documents during training and batch classification. Zero means
+	// number of CPUs. Mystem pool size limits concurrency if pool is used.
+	ExtractionWorkers int
+
+	// StopWordsFiles are stop words lists merged with built-in one. Lists
+	// are read by entity.ParseStopWords.
+	StopWordsFiles []string
+
+	// DataDir is a directory where models are persisted. Directory of
+	// ClassifierFilePath is used if it's empty.
+	DataDir string
+
+	// ClassifierFilePath is a file path of default model. It's persisted to
+	// data directory if ClassifierFilePath is empty.
+	ClassifierFilePath string
+
+	// SnapshotsCount is a number of the last trained model snapshots kept.
+	// Zero disables snapshots.
+	SnapshotsCount int
+
+	// ModelConfig is a configuration of models which aren't configured.
+	ModelConfig entity.ModelConfig
+
+	// AutosaveInterval is an interval of saving changed models. Zero
+	// disables autosave. Models are saved after training regardless.
+	AutosaveInterval time.Duration
+
+	WebServerBindAddr string
+	WebServerDebug    bool
+
+	// RequestTimeout is a maximal duration of web server request. Zero
+	// means no timeout.
+	RequestTimeout time.Duration
+
+	// ExtractionCacheSize is a number of the last words extraction results
+	// cached. Zero disables cache.
+	ExtractionCacheSize int
+
+	// ExtractionCacheFilePath is a file where cache is persisted between
+	// restarts. Cache is kept in memory only if it's empty.
+	ExtractionCacheFilePath string
+}
+
 type Service struct {
-	classifier         *Classifier
-	classifierFilePath string
-	webServer          *web.Server
+	wordsExtractor   WordsExtractor
+	cache            *ExtractionCache
+	cacheFilePath    string
+	registry         *Registry
+	webServer        *web.Server
+	autosaveInterval time.Duration
+
+	stop      chan struct{}
+	waitGroup sync.WaitGroup
+
+	log *logrus.Entry
 }
 
-func NewService(mystemBinPath string, classifierFilePath string,
-	webServerBindAddr string, webServerDebug bool) (*Service, error) {
+func NewService(cfg Config) (*Service, error) {
+	we, err := newWordsExtractor(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	stopWords, err := loadStopWords(cfg.StopWordsFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	log := logrus.WithField("subsystem", "service")
+
+	f := newWordsExtractorFactory(we, stopWords)
+
+	var cache *ExtractionCache
+
+	if cfg.ExtractionCacheSize > 0 {
+		cache, err = NewExtractionCache(cfg.ExtractionCacheSize)
+		if err != nil {
+			return nil, errors.New("failed to create cache: " + err.Error())
+		}
 
-	c := NewClassifier(mystem.NewWordsExtractor(mystemBinPath))
+		if cfg.ExtractionCacheFilePath != "" {
+			err = cache.Load(cfg.ExtractionCacheFilePath)
+			if err != nil && !os.IsNotExist(err) {
+				log.WithError(err).Warning("failed to load cache")
+			}
+		}
 
-	s := &Service{
-		classifier:         c,
-		classifierFilePath: classifierFilePath,
-		webServer:          web.NewServer(webServerBindAddr, c, webServerDebug),
+		f = cachingWordsExtractorFactory(f, cache)
 	}
 
-	classifierFileStat, err := os.Stat(classifierFilePath)
-	if !os.IsNotExist(err) && !classifierFileStat.IsDir() {
-		c.Load(classifierFilePath)
+	dataDir := cfg.DataDir
+	if dataDir == "" && cfg.ClassifierFilePath != "" {
+		dataDir = filepath.Dir(cfg.ClassifierFilePath)
 	}
 
-	return s, nil
+	r, err := NewRegistry(RegistryConfig{
+		DataDir:              dataDir,
+		DefaultModelFilePath: cfg.ClassifierFilePath,
+		KeepSnapshots:        cfg.SnapshotsCount,
+		ModelConfig:          cfg.ModelConfig,
+		Workers:              cfg.ExtractionWorkers,
+	}, f)
+	if err != nil {
+		return nil, errors.New("failed to create registry: " + err.Error())
+	}
+
+	return &Service{
+		wordsExtractor: we,
+		cache:          cache,
+		cacheFilePath:  cfg.ExtractionCacheFilePath,
+		registry:       r,
+		webServer: web.NewServer(cfg.WebServerBindAddr,
+			webModels{registry: r, cache: cache}, DefaultModelName,
+			cfg.WebServerDebug, cfg.RequestTimeout),
+		autosaveInterval: cfg.AutosaveInterval,
+
+		stop: make(chan struct{}),
+
+		log: log,
+	}, nil
+}
+
+// cachingWordsExtractorFactory returns factory of words extractors created
+// by f which cache their results in cache.
+func cachingWordsExtractorFactory(f WordsExtractorFactory,
+	cache *ExtractionCache) WordsExtractorFactory {
+
+	return func(o entity.ExtractionOptions) (WordsExtractor, error) {
+		we, err := f(o)
+		if err != nil {
+			return nil, err
+		}
+		return cache.Wrap(we), nil
+	}
+}
+
+func newWordsExtractor(cfg Config) (WordsExtractor, error) {
+	switch cfg.WordsExtractor {
+	case "", MystemWordsExtractor:
+		if cfg.MystemPoolSize > 0 {
+			pool, err := mystem.NewPool(cfg.MystemBinPath,
+				cfg.MystemPoolSize)
+			if err != nil {
+				return nil, errors.New("failed to create mystem pool: " +
+					err.Error())
+			}
+			return pool, nil
+		}
+		return mystem.NewWordsExtractor(cfg.MystemBinPath), nil
+	case StemmerWordsExtractor:
+		return stemmer.NewWordsExtractor(), nil
+	default:
+		return nil, errors.New("unknown words extractor: " +
+			cfg.WordsExtractor)
+	}
+}
+
+// loadStopWords returns built-in stop words merged with stop words of files.
+func loadStopWords(files []string) (*mystem.StopWords, error) {
+	stopWords := mystem.BuiltinStopWords()
+
+	for _, path := range files {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, errors.New("failed to open stop words file: " +
+				err.Error())
+		}
+
+		sws, err := entity.ParseStopWords(f)
+		f.Close()
+		if err != nil {
+			return nil, errors.New("failed to parse stop words file " +
+				path + ": " + err.Error())
+		}
+
+		stopWords = stopWords.Merge(mystem.NewStopWords(sws))
+	}
+
+	return stopWords, nil
+}
+
+// newWordsExtractorFactory returns factory of words extractors which filter
+// words of we. Model stop words are merged with stopWords or replace them.
+func newWordsExtractorFactory(we WordsExtractor,
+	stopWords *mystem.StopWords) WordsExtractorFactory {
+
+	return func(o entity.ExtractionOptions) (WordsExtractor, error) {
+		f := mystem.Filter{
+			StopWords:       stopWords,
+			PartsOfSpeech:   o.PartsOfSpeech,
+			DropProperNames: o.DropProperNames,
+			DropNumerals:    o.DropNumerals,
+		}
+
+		modelStopWords := mystem.NewStopWords(o.StopWords)
+
+		if o.StopWordsMode == entity.StopWordsReplace {
+			f.StopWords = modelStopWords
+		} else if len(o.StopWords) > 0 {
+			f.StopWords = stopWords.Merge(modelStopWords)
+		}
+
+		switch we := we.(type) {
+		case *mystem.Pool:
+			return we.WithFilter(f), nil
+		case *mystem.WordsExtractor:
+			return we.WithFilter(f), nil
+		case *stemmer.WordsExtractor:
+			swe, err := we.WithFilter(f)
+			if err != nil {
+				return nil, err
+			}
+			return swe, nil
+		default:
+			return StaticWordsExtractorFactory(we)(o)
+		}
+	}
 }
 
 func (s *Service) Start() {
 	s.webServer.Start()
+
+	if s.autosaveInterval > 0 {
+		s.waitGroup.Add(1)
+		go func() {
+			defer s.waitGroup.Done()
+			s.autosave()
+		}()
+	}
+}
+
+func (s *Service) autosave() {
+	ticker := time.NewTicker(s.autosaveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			err := s.registry.Save()
+			if err != nil {
+				s.log.WithError(err).Error("failed to autosave models")
+			}
+			s.saveCache()
+		case <-s.stop:
+			return
+		}
+	}
 }
 
 func (s *Service) Stop() {
+	close(s.stop)
+	s.waitGroup.Wait()
+
 	s.webServer.Stop()
-	s.classifier.Save(s.classifierFilePath)
+	s.registry.Wait()
+
+	err := s.registry.Save()
+	if err != nil {
+		s.log.WithError(err).Error("failed to save models")
+	}
+
+	s.saveCache()
+
+	if closer, ok := s.wordsExtractor.(io.Closer); ok {
+		err := closer.Close()
+		if err != nil {
+			s.log.WithError(err).Error("failed to close words extractor")
+		}
+	}
+}
+
+// saveCache persists words extraction cache if it's configured.
+func (s *Service) saveCache() {
+	if s.cache == nil || s.cacheFilePath == "" {
+		return
+	}
+
+	err := s.cache.Save(s.cacheFilePath)
+	if err != nil {
+		s.log.WithError(err).Error("failed to save cache")
+	}
+}
+
+// webModels adapts registry to web server.
+type webModels struct {
+	registry *Registry
+	cache    *ExtractionCache
+}
+
+func (wm webModels) Model(name string) (web.Classifier, bool) {
+	m, err := wm.registry.Model(name)
+	if err != nil {
+		return nil, false
+	}
+	return m, true
+}
+
+func (wm webModels) CreateModel(name string) error {
+	_, err := wm.registry.Create(name)
+	return err
+}
+
+func (wm webModels) DeleteModel(name string) error {
+	return wm.registry.Delete(name)
+}
+
+func (wm webModels) ModelNames() []string {
+	return wm.registry.Names()
+}
+
+func (wm webModels) CacheStats() (entity.CacheStats, bool) {
+	if wm.cache == nil {
+		return entity.CacheStats{}, false
+	}
+	return wm.cache.Stats(), true
 }
@@ -0,0 +1,265 @@
+package classifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dimuls/classifier/entity"
+)
+
+const (
+	// snapshotsDirSuffix is appended to model file path to get directory of
+	// model snapshots.
+	snapshotsDirSuffix = ".snapshots"
+
+	snapshotModelFileName = "model"
+	snapshotFileName      = "snapshot.json"
+
+	snapshotIDLayout = "20060102T150405.000000000"
+)
+
+var snapshotIDRegexp = regexp.MustCompile(`^[0-9]{8}T[0-9]{6}\.[0-9]{9}$`)
+
+var ErrSnapshotNotFound = errors.New("snapshot not found")
+
+// snapshots keeps the last snapshots of model. Every snapshot is a
+// directory with model file, its metadata and snapshot description.
+type snapshots struct {
+	dir   string
+	keep  int
+	mutex sync.Mutex
+}
+
+func newSnapshots(dir string, keep int) *snapshots {
+	return &snapshots{
+		dir:  dir,
+		keep: keep,
+	}
+}
+
+// Create saves encoded classifier to new snapshot and removes the oldest
+// snapshots above the limit. Nothing is saved if keep limit is not positive.
+func (ss *snapshots) Create(ec *encodedClassifier,
+	trainingJobID string) error {
+
+	if ss.keep <= 0 {
+		return nil
+	}
+
+	ss.mutex.Lock()
+	defer ss.mutex.Unlock()
+
+	now := time.Now()
+	id := now.UTC().Format(snapshotIDLayout)
+
+	// Snapshot is prepared in temporary directory and renamed, so partial
+	// snapshots are never listed.
+	tmpDir := filepath.Join(ss.dir, id+".tmp")
+
+	err := os.MkdirAll(tmpDir, 0755)
+	if err != nil {
+		return errors.New("failed to create snapshot dir: " + err.Error())
+	}
+
+	defer os.RemoveAll(tmpDir)
+
+	err = ec.write(filepath.Join(tmpDir, snapshotModelFileName))
+	if err != nil {
+		return errors.New("failed to save classifier: " + err.Error())
+	}
+
+	snapshot := entity.Snapshot{
+		ID:            id,
+		Created:       now,
+		TrainingJobID: trainingJobID,
+		Metadata:      ec.meta,
+	}
+
+	err = writeFileAtomic(filepath.Join(tmpDir, snapshotFileName),
+		func(w io.Writer) error {
+			return json.NewEncoder(w).Encode(snapshot)
+		})
+	if err != nil {
+		return errors.New("failed to write snapshot file: " + err.Error())
+	}
+
+	err = os.Rename(tmpDir, filepath.Join(ss.dir, id))
+	if err != nil {
+		return errors.New("failed to rename snapshot dir: " + err.Error())
+	}
+
+	syncDir(ss.dir)
+
+	return ss.prune()
+}
+
+// prune removes the oldest snapshots above the limit. Should be called with
+// mutex locked.
+func (ss *snapshots) prune() error {
+	list, err := ss.list()
+	if err != nil {
+		return err
+	}
+
+	if len(list) <= ss.keep {
+		return nil
+	}
+
+	for _, s := range list[ss.keep:] {
+		err = os.RemoveAll(filepath.Join(ss.dir, s.ID))
+		if err != nil {
+			return errors.New("failed to remove snapshot: " + err.Error())
+		}
+	}
+
+	return nil
+}
+
+// List returns snapshots ordered from the newest to the oldest.
+func (ss *snapshots) List() ([]entity.Snapshot, error) {
+	ss.mutex.Lock()
+	defer ss.mutex.Unlock()
+
+	return ss.list()
+}
+
+func (ss *snapshots) list() ([]entity.Snapshot, error) {
+	files, err := ioutil.ReadDir(ss.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.New("failed to read snapshots dir: " + err.Error())
+	}
+
+	var list []entity.Snapshot
+
+	for _, f := range files {
+		if !f.IsDir() || !snapshotIDRegexp.MatchString(f.Name()) {
+			continue
+		}
+
+		s, err := ss.read(f.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, s)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ID > list[j].ID
+	})
+
+	return list, nil
+}
+
+func (ss *snapshots) read(id string) (entity.Snapshot, error) {
+	var s entity.Snapshot
+
+	f, err := os.Open(filepath.Join(ss.dir, id, snapshotFileName))
+	if err != nil {
+		return s, errors.New("failed to open snapshot file: " + err.Error())
+	}
+
+	defer f.Close()
+
+	err = json.NewDecoder(f).Decode(&s)
+	if err != nil {
+		return s, errors.New("failed to decode snapshot file: " + err.Error())
+	}
+
+	return s, nil
+}
+
+// Restore loads snapshot model to classifier.
+func (ss *snapshots) Restore(c *Classifier, id string) error {
+	if !snapshotIDRegexp.MatchString(id) {
+		return ErrSnapshotNotFound
+	}
+
+	return ss.restore(c, id)
+}
+
+func (ss *snapshots) restore(c *Classifier, id string) error {
+	ss.mutex.Lock()
+	defer ss.mutex.Unlock()
+
+	modelPath := filepath.Join(ss.dir, id, snapshotModelFileName)
+
+	_, err := os.Stat(modelPath)
+	if os.IsNotExist(err) {
+		return ErrSnapshotNotFound
+	}
+
+	return c.Load(modelPath)
+}
+
+// Evaluate evaluates snapshot model on documents and saves evaluation to
+// snapshot. Snapshot model is loaded to new classifier configured like c, so
+// c isn't changed.
+func (ss *snapshots) Evaluate(ctx context.Context, c *Classifier, id string,
+	docs []entity.Document) (entity.Evaluation, error) {
+
+	if !snapshotIDRegexp.MatchString(id) {
+		return entity.Evaluation{}, ErrSnapshotNotFound
+	}
+
+	sc := NewConfigurableClassifier(c.newWordsExtractor)
+	sc.config = c.Config()
+	sc.workers = c.workers
+
+	err := ss.restore(sc, id)
+	if err != nil {
+		return entity.Evaluation{}, err
+	}
+
+	e, err := sc.Evaluate(ctx, docs)
+	if err != nil {
+		return entity.Evaluation{}, err
+	}
+
+	ss.mutex.Lock()
+	defer ss.mutex.Unlock()
+
+	// Snapshot could be pruned while it's evaluated.
+	_, err = os.Stat(filepath.Join(ss.dir, id))
+	if os.IsNotExist(err) {
+		return entity.Evaluation{}, ErrSnapshotNotFound
+	}
+
+	s, err := ss.read(id)
+	if err != nil {
+		return entity.Evaluation{}, err
+	}
+
+	s.Evaluation = &e
+
+	err = writeFileAtomic(filepath.Join(ss.dir, id, snapshotFileName),
+		func(w io.Writer) error {
+			return json.NewEncoder(w).Encode(s)
+		})
+	if err != nil {
+		return entity.Evaluation{}, errors.New(
+			"failed to write snapshot file: " + err.Error())
+	}
+
+	return e, nil
+}
+
+// Remove removes all snapshots.
+func (ss *snapshots) Remove() error {
+	ss.mutex.Lock()
+	defer ss.mutex.Unlock()
+
+	return os.RemoveAll(ss.dir)
+}
@@ -0,0 +1,188 @@
+package classifier
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/dimuls/classifier/entity"
+)
+
+func encode(t *testing.T, c *Classifier) *encodedClassifier {
+	ec, err := c.encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ec
+}
+
+func TestSnapshots(t *testing.T) {
+	dir, remove := newTempDir(t)
+	defer remove()
+
+	ss := newSnapshots(filepath.Join(dir, "snapshots"), 2)
+
+	for i, jobID := range []string{"1", "2", "3"} {
+		err := ss.Create(encode(t, newTrainedClassifier(t, testDocs[i:])), jobID)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	list, err := ss.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The oldest snapshot is pruned.
+	if len(list) != 2 {
+		t.Fatalf("got %d snapshots, want 2", len(list))
+	}
+	if list[0].TrainingJobID != "3" || list[1].TrainingJobID != "2" {
+		t.Errorf("got snapshots of jobs %q and %q, want 3 and 2",
+			list[0].TrainingJobID, list[1].TrainingJobID)
+	}
+	if list[1].Metadata.Documents != len(testDocs)-1 {
+		t.Errorf("got %d documents in snapshot metadata, want %d",
+			list[1].Metadata.Documents, len(testDocs)-1)
+	}
+
+	c := newTrainedClassifier(t, testDocs)
+
+	err = ss.Restore(c, list[1].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if learned := c.classifier.Learned(); learned != len(testDocs)-1 {
+		t.Errorf("restored classifier learned %d documents, want %d",
+			learned, len(testDocs)-1)
+	}
+}
+
+func TestSnapshotsNotFound(t *testing.T) {
+	dir, remove := newTempDir(t)
+	defer remove()
+
+	ss := newSnapshots(dir, 2)
+
+	c := NewClassifier(fieldsWordsExtractor{})
+
+	for _, id := range []string{"20180102T150405.000000000", "..", ""} {
+		err := ss.Restore(c, id)
+		if err != ErrSnapshotNotFound {
+			t.Errorf("restore of snapshot %q got error %v, want %v", id,
+				err, ErrSnapshotNotFound)
+		}
+	}
+}
+
+func TestSnapshotsDisabled(t *testing.T) {
+	dir, remove := newTempDir(t)
+	defer remove()
+
+	ss := newSnapshots(filepath.Join(dir, "snapshots"), 0)
+
+	err := ss.Create(encode(t, newTrainedClassifier(t, testDocs)), "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := ss.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 0 {
+		t.Errorf("got %d snapshots, want none", len(list))
+	}
+}
+
+func TestSnapshotsEvaluate(t *testing.T) {
+	dir, remove := newTempDir(t)
+	defer remove()
+
+	ss := newSnapshots(filepath.Join(dir, "snapshots"), 2)
+
+	err := ss.Create(encode(t, newTrainedClassifier(t, testDocs)), "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := ss.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if list[0].Evaluation != nil {
+		t.Error("new snapshot is evaluated")
+	}
+
+	// Classifier evaluating snapshot differs from snapshot model.
+	c := newTrainedClassifier(t, testDocs[1:3])
+
+	e, err := ss.Evaluate(context.Background(), c, list[0].ID, testDocs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e.Documents != len(testDocs) || e.Accuracy != 1 {
+		t.Errorf("got evaluation of %d documents with accuracy %v, want "+
+			"%d documents with accuracy 1", e.Documents, e.Accuracy,
+			len(testDocs))
+	}
+
+	if learned := c.classifier.Learned(); learned != 2 {
+		t.Error("evaluating classifier is changed")
+	}
+
+	list, err = ss.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if list[0].Evaluation == nil ||
+		list[0].Evaluation.Accuracy != e.Accuracy {
+		t.Errorf("got snapshot evaluation %+v, want %+v",
+			list[0].Evaluation, e)
+	}
+
+	_, err = ss.Evaluate(context.Background(), c,
+		"20180102T150405.000000000", testDocs)
+	if err != ErrSnapshotNotFound {
+		t.Errorf("got error %v, want %v", err, ErrSnapshotNotFound)
+	}
+}
+
+func TestModelSnapshotIsSaved(t *testing.T) {
+	dir, remove := newTempDir(t)
+	defer remove()
+
+	path := filepath.Join(dir, "model")
+
+	m := newModel("model", path,
+		StaticWordsExtractorFactory(fieldsWordsExtractor{}), 2,
+		entity.ModelConfig{})
+
+	m.StartTraining(testDocs)
+	m.Wait()
+
+	meta, err := ReadMetadata(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := m.Snapshots()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 {
+		t.Fatalf("got %d snapshots, want 1", len(list))
+	}
+
+	// Snapshot is created from the same encoded classifier as model file.
+	if list[0].Metadata.Checksum != meta.Checksum ||
+		!list[0].Metadata.Created.Equal(meta.Created) {
+		t.Errorf("snapshot metadata %+v differs from model metadata %+v",
+			list[0].Metadata, meta)
+	}
+}
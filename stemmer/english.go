@@ -0,0 +1,334 @@
+package stemmer
+
+import "strings"
+
+var englishExceptions = map[string]string{
+	"skis":   "ski",
+	"skies":  "sky",
+	"dying":  "die",
+	"lying":  "lie",
+	"tying":  "tie",
+	"idly":   "idl",
+	"gently": "gentl",
+	"ugly":   "ugli",
+	"early":  "earli",
+	"only":   "onli",
+	"singly": "singl",
+	"sky":    "sky",
+	"news":   "news",
+	"howe":   "howe",
+	"atlas":  "atlas",
+	"cosmos": "cosmos",
+	"bias":   "bias",
+	"andes":  "andes",
+}
+
+// englishStep1aExceptions are left as is after step 1a.
+var englishStep1aExceptions = map[string]struct{}{
+	"inning":  {},
+	"outing":  {},
+	"canning": {},
+	"herring": {},
+	"earring": {},
+	"proceed": {},
+	"exceed":  {},
+	"succeed": {},
+}
+
+var englishStep2 = map[string]string{
+	"tional":  "tion",
+	"enci":    "ence",
+	"anci":    "ance",
+	"abli":    "able",
+	"entli":   "ent",
+	"izer":    "ize",
+	"ization": "ize",
+	"ational": "ate",
+	"ation":   "ate",
+	"ator":    "ate",
+	"alism":   "al",
+	"aliti":   "al",
+	"alli":    "al",
+	"fulness": "ful",
+	"ousli":   "ous",
+	"ousness": "ous",
+	"iveness": "ive",
+	"iviti":   "ive",
+	"biliti":  "ble",
+	"bli":     "ble",
+	"ogi":     "og",
+	"fulli":   "ful",
+	"lessli":  "less",
+	"li":      "",
+}
+
+var englishStep3 = map[string]string{
+	"tional":  "tion",
+	"ational": "ate",
+	"alize":   "al",
+	"icate":   "ic",
+	"iciti":   "ic",
+	"ical":    "ic",
+	"ful":     "",
+	"ness":    "",
+	"ative":   "",
+}
+
+var englishStep4 = []string{"al", "ance", "ence", "er", "ic", "able", "ible",
+	"ant", "ement", "ment", "ent", "ism", "ate", "iti", "ous", "ive", "ize",
+	"ion"}
+
+// StemEnglish returns stem of lower case english word using Snowball
+// English (Porter2) stemming algorithm.
+func StemEnglish(word string) string {
+	if len(word) <= 2 {
+		return word
+	}
+
+	if s, ok := englishExceptions[word]; ok {
+		return s
+	}
+
+	w := []byte(strings.TrimPrefix(word, "'"))
+
+	// Mark consonant y.
+	for i := range w {
+		if w[i] == 'y' && (i == 0 || isEnglishVowel(w[i-1])) {
+			w[i] = 'Y'
+		}
+	}
+
+	r1, r2 := englishRegions(w)
+
+	w = englishStep0(w)
+	w = englishStep1a(w)
+
+	if _, ok := englishStep1aExceptions[string(w)]; ok {
+		return string(w)
+	}
+
+	w = englishStep1b(w, r1)
+	w = englishStep1c(w)
+	w = englishReplace(w, r1, r2, englishStep2)
+	w = englishReplace(w, r1, r2, englishStep3)
+	w = englishStep4Remove(w, r2)
+	w = englishStep5(w, r1, r2)
+
+	return strings.Replace(string(w), "Y", "y", -1)
+}
+
+func isEnglishVowel(b byte) bool {
+	return strings.IndexByte("aeiouy", b) >= 0
+}
+
+// englishRegions returns starts of R1 and R2 regions of word.
+func englishRegions(w []byte) (r1 int, r2 int) {
+	r1 = len(w)
+
+	s := string(w)
+	for _, prefix := range []string{"gener", "commun", "arsen"} {
+		if strings.HasPrefix(s, prefix) {
+			r1 = len(prefix)
+			break
+		}
+	}
+
+	if r1 == len(w) {
+		r1 = englishRegionStart(w, 0)
+	}
+
+	return r1, englishRegionStart(w, r1)
+}
+
+// englishRegionStart returns position after the first non-vowel following
+// a vowel starting from i.
+func englishRegionStart(w []byte, i int) int {
+	for ; i+1 < len(w); i++ {
+		if isEnglishVowel(w[i]) && !isEnglishVowel(w[i+1]) {
+			return i + 2
+		}
+	}
+	return len(w)
+}
+
+// endsWithShortSyllable reports whether word ends with a short syllable.
+func endsWithShortSyllable(w []byte) bool {
+	n := len(w)
+	if n == 2 {
+		return isEnglishVowel(w[0]) && !isEnglishVowel(w[1])
+	}
+	return n >= 3 && !isEnglishVowel(w[n-3]) && isEnglishVowel(w[n-2]) &&
+		!isEnglishVowel(w[n-1]) && strings.IndexByte("wxY", w[n-1]) < 0
+}
+
+func containsEnglishVowel(w []byte) bool {
+	for _, b := range w {
+		if isEnglishVowel(b) {
+			return true
+		}
+	}
+	return false
+}
+
+// longestEnglishSuffix returns the longest of suffixes which word has.
+func longestEnglishSuffix(w []byte, suffixes []string) (string, bool) {
+	var (
+		longest string
+		found   bool
+	)
+	for _, s := range suffixes {
+		if len(s) > len(longest) && strings.HasSuffix(string(w), s) {
+			longest = s
+			found = true
+		}
+	}
+	return longest, found
+}
+
+func englishStep0(w []byte) []byte {
+	s, ok := longestEnglishSuffix(w, []string{"'", "'s", "'s'"})
+	if !ok {
+		return w
+	}
+	return w[:len(w)-len(s)]
+}
+
+func englishStep1a(w []byte) []byte {
+	s, ok := longestEnglishSuffix(w, []string{"sses", "ied", "ies", "s",
+		"us", "ss"})
+	if !ok {
+		return w
+	}
+
+	switch s {
+	case "sses":
+		return w[:len(w)-2]
+	case "ied", "ies":
+		if len(w) > 4 {
+			return append(w[:len(w)-3], 'i')
+		}
+		return w[:len(w)-1]
+	case "s":
+		if containsEnglishVowel(w[:len(w)-2]) {
+			return w[:len(w)-1]
+		}
+	}
+
+	return w
+}
+
+func englishStep1b(w []byte, r1 int) []byte {
+	s, ok := longestEnglishSuffix(w, []string{"eed", "eedly", "ed", "edly",
+		"ing", "ingly"})
+	if !ok {
+		return w
+	}
+
+	switch s {
+	case "eed", "eedly":
+		if len(w)-len(s) >= r1 {
+			return append(w[:len(w)-len(s)], 'e', 'e')
+		}
+		return w
+	}
+
+	stem := w[:len(w)-len(s)]
+	if !containsEnglishVowel(stem) {
+		return w
+	}
+
+	w = stem
+
+	n := len(w)
+	switch {
+	case strings.HasSuffix(string(w), "at"), strings.HasSuffix(string(w), "bl"),
+		strings.HasSuffix(string(w), "iz"):
+		w = append(w, 'e')
+	case n >= 2 && w[n-1] == w[n-2] &&
+		strings.IndexByte("bdfgmnprt", w[n-1]) >= 0:
+		w = w[:n-1]
+	case r1 >= n && endsWithShortSyllable(w):
+		w = append(w, 'e')
+	}
+
+	return w
+}
+
+func englishStep1c(w []byte) []byte {
+	n := len(w)
+	if n > 2 && (w[n-1] == 'y' || w[n-1] == 'Y') && !isEnglishVowel(w[n-2]) {
+		w[n-1] = 'i'
+	}
+	return w
+}
+
+// englishReplace replaces the longest of suffixes in R1 by its replacement.
+func englishReplace(w []byte, r1, r2 int,
+	replacements map[string]string) []byte {
+
+	suffixes := make([]string, 0, len(replacements))
+	for s := range replacements {
+		suffixes = append(suffixes, s)
+	}
+
+	s, ok := longestEnglishSuffix(w, suffixes)
+	if !ok || len(w)-len(s) < r1 {
+		return w
+	}
+
+	stem := w[:len(w)-len(s)]
+
+	switch s {
+	case "ogi":
+		if len(stem) == 0 || stem[len(stem)-1] != 'l' {
+			return w
+		}
+	case "li":
+		if len(stem) == 0 ||
+			strings.IndexByte("cdeghkmnrt", stem[len(stem)-1]) < 0 {
+			return w
+		}
+	case "ative":
+		if len(stem) < r2 {
+			return w
+		}
+	}
+
+	return append(stem, replacements[s]...)
+}
+
+func englishStep4Remove(w []byte, r2 int) []byte {
+	s, ok := longestEnglishSuffix(w, englishStep4)
+	if !ok || len(w)-len(s) < r2 {
+		return w
+	}
+
+	stem := w[:len(w)-len(s)]
+
+	if s == "ion" && (len(stem) == 0 ||
+		(stem[len(stem)-1] != 's' && stem[len(stem)-1] != 't')) {
+		return w
+	}
+
+	return stem
+}
+
+func englishStep5(w []byte, r1, r2 int) []byte {
+	n := len(w)
+	if n == 0 {
+		return w
+	}
+
+	switch w[n-1] {
+	case 'e':
+		if n-1 >= r2 || (n-1 >= r1 && !endsWithShortSyllable(w[:n-1])) {
+			return w[:n-1]
+		}
+	case 'l':
+		if n-1 >= r2 && n >= 2 && w[n-2] == 'l' {
+			return w[:n-1]
+		}
+	}
+
+	return w
+}
@@ -0,0 +1,200 @@
+package stemmer
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Suffix groups of Snowball Russian stemming algorithm. Suffixes of the
+// first group of a kind should be preceded by "а" or "я", which are kept.
+var (
+	perfectiveGerunds1 = []string{"в", "вши", "вшись"}
+	perfectiveGerunds2 = []string{"ив", "ивши", "ившись", "ыв", "ывши",
+		"ывшись"}
+
+	adjectives = []string{"ее", "ие", "ые", "ое", "ими", "ыми", "ей", "ий",
+		"ый", "ой", "ем", "им", "ым", "ом", "его", "ого", "ему", "ому", "их",
+		"ых", "ую", "юю", "ая", "яя", "ою", "ею"}
+
+	participles1 = []string{"ем", "нн", "вш", "ющ", "щ"}
+	participles2 = []string{"ивш", "ывш", "ующ"}
+
+	reflexives = []string{"ся", "сь"}
+
+	verbs1 = []string{"ла", "на", "ете", "йте", "ли", "й", "л", "ем", "н",
+		"ло", "но", "ет", "ют", "ны", "ть", "ешь", "нно"}
+	verbs2 = []string{"ила", "ыла", "ена", "ейте", "уйте", "ите", "или",
+		"ыли", "ей", "уй", "ил", "ыл", "им", "ым", "ен", "ило", "ыло", "ено",
+		"ят", "ует", "уют", "ит", "ыт", "ены", "ить", "ыть", "ишь", "ую", "ю"}
+
+	nouns = []string{"а", "ев", "ов", "ие", "ье", "е", "иями", "ями", "ами",
+		"еи", "ии", "и", "ией", "ей", "ой", "ий", "й", "иям", "ям", "ием",
+		"ем", "ам", "ом", "о", "у", "ах", "иях", "ях", "ы", "ь", "ию", "ью",
+		"ю", "ия", "ья", "я"}
+
+	superlatives = []string{"ейш", "ейше"}
+
+	derivationals = []string{"ост", "ость"}
+)
+
+// StemRussian returns stem of lower case russian word using Snowball
+// Russian stemming algorithm.
+func StemRussian(word string) string {
+	w := []rune(strings.Replace(word, "ё", "е", -1))
+
+	rv, r2 := russianRegions(w)
+
+	// Step 1.
+	if s, ok := removeGrouped(w, rv, perfectiveGerunds1,
+		perfectiveGerunds2); ok {
+		w = s
+	} else {
+		if s, ok := removeLongest(w, rv, reflexives); ok {
+			w = s
+		}
+		if s, ok := removeAdjectival(w, rv); ok {
+			w = s
+		} else if s, ok := removeGrouped(w, rv, verbs1, verbs2); ok {
+			w = s
+		} else if s, ok := removeLongest(w, rv, nouns); ok {
+			w = s
+		}
+	}
+
+	// Step 2.
+	if s, ok := removeLongest(w, rv, []string{"и"}); ok {
+		w = s
+	}
+
+	// Step 3.
+	if s, ok := removeLongest(w, r2, derivationals); ok {
+		w = s
+	}
+
+	// Step 4.
+	if s, ok := removeLongest(w, rv, superlatives); ok {
+		w = s
+	}
+	if hasSuffix(w, rv, "нн") {
+		w = w[:len(w)-1]
+	} else if s, ok := removeLongest(w, rv, []string{"ь"}); ok {
+		w = s
+	}
+
+	return string(w)
+}
+
+func isRussianVowel(r rune) bool {
+	return strings.ContainsRune("аеиоуыэюя", r)
+}
+
+// russianRegions returns starts of RV and R2 regions of word.
+func russianRegions(w []rune) (rv int, r2 int) {
+	rv, r2 = len(w), len(w)
+
+	i := 0
+	for i < len(w) && !isRussianVowel(w[i]) {
+		i++
+	}
+	if i == len(w) {
+		return
+	}
+	rv = i + 1
+
+	r1 := len(w)
+	for i = rv; i < len(w); i++ {
+		if !isRussianVowel(w[i]) {
+			r1 = i + 1
+			break
+		}
+	}
+
+	for i = r1; i < len(w); i++ {
+		if isRussianVowel(w[i]) {
+			break
+		}
+	}
+	for i++; i < len(w); i++ {
+		if !isRussianVowel(w[i]) {
+			r2 = i + 1
+			break
+		}
+	}
+
+	return
+}
+
+// hasSuffix reports whether word has suffix which starts not before start.
+func hasSuffix(w []rune, start int, suffix string) bool {
+	n := utf8.RuneCountInString(suffix)
+	if len(w)-n < start {
+		return false
+	}
+	return string(w[len(w)-n:]) == suffix
+}
+
+// longestSuffix returns the longest of suffixes which word has after start.
+func longestSuffix(w []rune, start int, suffixes []string) (string, bool) {
+	var (
+		longest string
+		found   bool
+	)
+	for _, s := range suffixes {
+		if utf8.RuneCountInString(s) > utf8.RuneCountInString(longest) &&
+			hasSuffix(w, start, s) {
+			longest = s
+			found = true
+		}
+	}
+	return longest, found
+}
+
+func removeLongest(w []rune, start int, suffixes []string) ([]rune, bool) {
+	s, found := longestSuffix(w, start, suffixes)
+	if !found {
+		return w, false
+	}
+	return w[:len(w)-utf8.RuneCountInString(s)], true
+}
+
+// removeGrouped removes the longest suffix of both groups. Suffix of the
+// first group is removed only if it's preceded by "а" or "я".
+func removeGrouped(w []rune, start int, group1, group2 []string) (
+	[]rune, bool) {
+
+	s1, found1 := longestSuffix(w, start, group1)
+	s2, found2 := longestSuffix(w, start, group2)
+
+	n1 := utf8.RuneCountInString(s1)
+	n2 := utf8.RuneCountInString(s2)
+
+	if found2 && n2 >= n1 {
+		return w[:len(w)-n2], true
+	}
+
+	if !found1 {
+		return w, false
+	}
+
+	i := len(w) - n1
+	if i-1 < start || (w[i-1] != 'а' && w[i-1] != 'я') {
+		return w, false
+	}
+
+	return w[:i], true
+}
+
+// removeAdjectival removes adjective suffix with optional preceding
+// participle suffix.
+func removeAdjectival(w []rune, start int) ([]rune, bool) {
+	w, ok := removeLongest(w, start, adjectives)
+	if !ok {
+		return w, false
+	}
+
+	if s, ok := removeGrouped(w, start, participles1, participles2); ok {
+		w = s
+	}
+
+	return w, true
+}
@@ -0,0 +1,200 @@
+package stemmer
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// Words and stems are taken from Snowball vocabularies of stemmers.
+
+func TestStemRussian(t *testing.T) {
+	tests := map[string]string{
+		"вавилон":      "вавилон",
+		"вагон":        "вагон",
+		"вагона":       "вагон",
+		"вагоне":       "вагон",
+		"вагонов":      "вагон",
+		"вагоном":      "вагон",
+		"вагоны":       "вагон",
+		"важная":       "важн",
+		"важнее":       "важн",
+		"важнейшие":    "важн",
+		"важнейшими":   "важн",
+		"важничаешь":   "важнича",
+		"важно":        "важн",
+		"важного":      "важн",
+		"важное":       "важн",
+		"важной":       "важн",
+		"важном":       "важн",
+		"важному":      "важн",
+		"важности":     "важност",
+		"важностию":    "важност",
+		"важность":     "важност",
+		"важностью":    "важност",
+		"важную":       "важн",
+		"важны":        "важн",
+		"важные":       "важн",
+		"важный":       "важн",
+		"важным":       "важн",
+		"важными":      "важн",
+		"важных":       "важн",
+		"вазах":        "ваз",
+		"вазы":         "ваз",
+		"вакса":        "вакс",
+		"вакханка":     "вакханк",
+		"вал":          "вал",
+		"валандался":   "валанда",
+		"валентина":    "валентин",
+		"валериановые": "валерианов",
+		"валерию":      "валер",
+		"валетами":     "валет",
+		"вали":         "вал",
+		"валил":        "вал",
+		"валился":      "вал",
+		"валится":      "вал",
+		"валов":        "вал",
+		"валяется":     "валя",
+		"валялась":     "валя",
+		"валялись":     "валя",
+		"валялось":     "валя",
+		"валялся":      "валя",
+		"валять":       "валя",
+		"валяются":     "валя",
+		"вам":          "вам",
+		"вами":         "вам",
+	}
+
+	for word, want := range tests {
+		if got := StemRussian(word); got != want {
+			t.Errorf("StemRussian(%q) = %q, want %q", word, got, want)
+		}
+	}
+}
+
+func TestStemEnglish(t *testing.T) {
+	tests := map[string]string{
+		"consign":       "consign",
+		"consigned":     "consign",
+		"consigning":    "consign",
+		"consignment":   "consign",
+		"consist":       "consist",
+		"consisted":     "consist",
+		"consistency":   "consist",
+		"consistent":    "consist",
+		"consistently":  "consist",
+		"consisting":    "consist",
+		"consists":      "consist",
+		"consolation":   "consol",
+		"consolations":  "consol",
+		"consolatory":   "consolatori",
+		"console":       "consol",
+		"consoled":      "consol",
+		"consoles":      "consol",
+		"consolidate":   "consolid",
+		"consolidated":  "consolid",
+		"consolidating": "consolid",
+		"consoling":     "consol",
+		"consolingly":   "consol",
+		"consols":       "consol",
+		"consonant":     "conson",
+		"consort":       "consort",
+		"consorted":     "consort",
+		"consorting":    "consort",
+		"conspicuous":   "conspicu",
+		"conspicuously": "conspicu",
+		"conspiracy":    "conspiraci",
+		"conspirator":   "conspir",
+		"conspirators":  "conspir",
+		"conspire":      "conspir",
+		"conspired":     "conspir",
+		"conspiring":    "conspir",
+		"constable":     "constabl",
+		"constables":    "constabl",
+		"constance":     "constanc",
+		"constancy":     "constanc",
+		"constant":      "constant",
+		"knack":         "knack",
+		"knackeries":    "knackeri",
+		"knacks":        "knack",
+		"knave":         "knave",
+		"knaves":        "knave",
+		"knavish":       "knavish",
+		"kneaded":       "knead",
+		"kneading":      "knead",
+		"knee":          "knee",
+		"kneel":         "kneel",
+		"kneeled":       "kneel",
+		"kneeling":      "kneel",
+		"knees":         "knee",
+		"knightly":      "knight",
+		"knitted":       "knit",
+		"knitting":      "knit",
+		"knives":        "knive",
+		"knocker":       "knocker",
+
+		// Exceptional forms.
+		"skis":      "ski",
+		"skies":     "sky",
+		"dying":     "die",
+		"lying":     "lie",
+		"tying":     "tie",
+		"idly":      "idl",
+		"gently":    "gentl",
+		"ugly":      "ugli",
+		"early":     "earli",
+		"only":      "onli",
+		"singly":    "singl",
+		"news":      "news",
+		"atlas":     "atlas",
+		"generate":  "generat",
+		"generous":  "generous",
+		"arsenal":   "arsenal",
+		"communism": "communism",
+	}
+
+	for word, want := range tests {
+		if got := StemEnglish(word); got != want {
+			t.Errorf("StemEnglish(%q) = %q, want %q", word, got, want)
+		}
+	}
+}
+
+func TestWordsExtractor(t *testing.T) {
+	we := NewWordsExtractor()
+
+	words, err := we.ExtractWords(
+		"Тяжелые вагоны, и consistent consoles 42! Вагон")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sort.Strings(words)
+
+	// Numbers and stop words are skipped.
+	want := []string{"consist", "consol", "вагон", "тяжел"}
+	if !reflect.DeepEqual(words, want) {
+		t.Errorf("ExtractWords() = %q, want %q", words, want)
+	}
+
+	tokens, err := we.ExtractTokens(
+		"Тяжелые вагоны, и consistent consoles 42! Вагон")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want = []string{"тяжел", "вагон", "consist", "consol", "вагон"}
+	if !reflect.DeepEqual(tokens, want) {
+		t.Errorf("ExtractTokens() = %q, want %q", tokens, want)
+	}
+
+	phrases, err := we.ExtractPhrases("Тяжелые вагоны, consistent consoles")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantPhrases := [][]string{{"тяжел", "вагон"}, {"consist", "consol"}}
+	if !reflect.DeepEqual(phrases, wantPhrases) {
+		t.Errorf("ExtractPhrases() = %q, want %q", phrases, wantPhrases)
+	}
+}
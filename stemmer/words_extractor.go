@@ -0,0 +1,212 @@
+package stemmer
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/dimuls/classifier/entity"
+	"github.com/dimuls/classifier/mystem"
+)
+
+// Name is a name of stemmer words extractor in its description.
+const Name = "stemmer"
+
+// WordsExtractor extracts word stems from text without external tools. It
+// stems russian words with Snowball Russian stemmer, english words with
+// Snowball English stemmer and keeps other words as is.
+type WordsExtractor struct {
+	stopWords    *mystem.StopWords
+	dropNumerals bool
+}
+
+func NewWordsExtractor() *WordsExtractor {
+	return &WordsExtractor{
+		stopWords: mystem.BuiltinStopWords(),
+	}
+}
+
+// WithFilter returns words extractor which filters words by f. Stemmer
+// doesn't know parts of speech, so only stop words and numerals can be
+// filtered.
+func (we *WordsExtractor) WithFilter(f mystem.Filter) (*WordsExtractor,
+	error) {
+
+	if len(f.PartsOfSpeech) > 0 || f.DropProperNames {
+		return nil, errors.New("stemmer doesn't support parts of speech")
+	}
+
+	stopWords := f.StopWords
+	if stopWords == nil {
+		stopWords = mystem.BuiltinStopWords()
+	}
+
+	return &WordsExtractor{
+		stopWords:    stopWords,
+		dropNumerals: f.DropNumerals,
+	}, nil
+}
+
+// skip reports whether word should be skipped.
+func (we *WordsExtractor) skip(word string) bool {
+	return we.stopWords.Contains(word) ||
+		(we.dropNumerals && strings.IndexFunc(word, unicode.IsDigit) >= 0)
+}
+
+// ExtractWords extracts sorted unique word stems of text.
+func (we *WordsExtractor) ExtractWords(text string) ([]string, error) {
+	if text == "" {
+		return nil, nil
+	}
+
+	wsMap := map[string]struct{}{}
+
+	for _, w := range tokenize(text) {
+		if we.skip(w) {
+			continue
+		}
+		wsMap[stem(w)] = struct{}{}
+	}
+
+	ws := make([]string, 0, len(wsMap))
+	for w := range wsMap {
+		ws = append(ws, w)
+	}
+
+	sort.Strings(ws)
+
+	return ws, nil
+}
+
+// ExtractTokens extracts word stems keeping their order and repetitions.
+func (we *WordsExtractor) ExtractTokens(text string) ([]string, error) {
+	var ws []string
+
+	for _, w := range tokenize(text) {
+		if we.skip(w) {
+			continue
+		}
+		ws = append(ws, stem(w))
+	}
+
+	return ws, nil
+}
+
+// ExtractPhrases extracts sequences of word stems which aren't interrupted
+// by stop words or punctuation.
+func (we *WordsExtractor) ExtractPhrases(text string) ([][]string, error) {
+	var phrases [][]string
+
+	for _, part := range strings.FieldsFunc(text, isPhraseDelimiter) {
+		var phrase []string
+
+		for _, w := range tokenize(part) {
+			if we.skip(w) {
+				if len(phrase) > 0 {
+					phrases = append(phrases, phrase)
+					phrase = nil
+				}
+				continue
+			}
+			phrase = append(phrase, stem(w))
+		}
+
+		if len(phrase) > 0 {
+			phrases = append(phrases, phrase)
+		}
+	}
+
+	return phrases, nil
+}
+
+// ExtractWordsContext extracts words if ctx isn't done. Stemming doesn't
+// block, so it isn't interrupted.
+func (we *WordsExtractor) ExtractWordsContext(ctx context.Context,
+	text string) ([]string, error) {
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return we.ExtractWords(text)
+}
+
+func (we *WordsExtractor) ExtractTokensContext(ctx context.Context,
+	text string) ([]string, error) {
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return we.ExtractTokens(text)
+}
+
+func (we *WordsExtractor) ExtractPhrasesContext(ctx context.Context,
+	text string) ([][]string, error) {
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return we.ExtractPhrases(text)
+}
+
+// isPhraseDelimiter reports whether r ends phrase. Hyphens and apostrophes
+// are parts of words.
+func isPhraseDelimiter(r rune) bool {
+	return unicode.IsPunct(r) && r != '-' && r != '\''
+}
+
+func (we *WordsExtractor) Describe() entity.WordsExtractorInfo {
+	settings := map[string]string{
+		"russian": "snowball",
+		"english": "porter2",
+	}
+
+	if we.dropNumerals {
+		settings["drop_numerals"] = "true"
+	}
+
+	return entity.WordsExtractorInfo{
+		Name:          Name,
+		Settings:      settings,
+		StopWordsHash: we.stopWords.Hash(),
+	}
+}
+
+// tokenize splits text to lower case words. Tokens without letters are
+// skipped.
+func tokenize(text string) []string {
+	var words []string
+
+	for _, t := range strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if strings.IndexFunc(t, unicode.IsLetter) < 0 {
+			continue
+		}
+		words = append(words, strings.ToLower(t))
+	}
+
+	return words
+}
+
+func stem(word string) string {
+	switch {
+	case isWritten(word, unicode.Cyrillic):
+		return StemRussian(word)
+	case isWritten(word, unicode.Latin):
+		return StemEnglish(word)
+	default:
+		return word
+	}
+}
+
+// isWritten reports whether all word letters are of script.
+func isWritten(word string, script *unicode.RangeTable) bool {
+	for _, r := range word {
+		if unicode.IsLetter(r) && !unicode.Is(script, r) {
+			return false
+		}
+	}
+	return true
+}
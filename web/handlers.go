@@ -1,9 +1,10 @@
 package web
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"net/http"
-	"sync/atomic"
 
 	"github.com/labstack/echo"
 
@@ -11,54 +12,580 @@ import (
 )
 
 func (s *Server) postTrain(c echo.Context) error {
-	if atomic.LoadInt32(&s.training) == 1 {
+	m, err := s.model(c)
+	if err != nil {
+		return err
+	}
+
+	var docs []entity.Document
+
+	err = c.Bind(&docs)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest,
+			"failed to bind body: "+err.Error())
+	}
+
+	err = entity.ValidateDocuments(docs)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusAccepted, m.StartTraining(docs))
+}
+
+func (s *Server) getTrainJobs(c echo.Context) error {
+	m, err := s.model(c)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, m.TrainingJobs())
+}
+
+func (s *Server) getTrainJob(c echo.Context) error {
+	m, err := s.model(c)
+	if err != nil {
+		return err
+	}
+
+	job, exists := m.TrainingJob(c.Param("id"))
+	if !exists {
+		return echo.NewHTTPError(http.StatusNotFound, "job not found")
+	}
+
+	return c.JSON(http.StatusOK, job)
+}
+
+func (s *Server) deleteTrainJob(c echo.Context) error {
+	m, err := s.model(c)
+	if err != nil {
+		return err
+	}
+
+	if !m.CancelTrainingJob(c.Param("id")) {
+		return echo.NewHTTPError(http.StatusNotFound, "job not found")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (s *Server) postLearn(c echo.Context) error {
+	m, err := s.model(c)
+	if err != nil {
+		return err
+	}
+
+	if m.Training() {
 		return echo.NewHTTPError(http.StatusServiceUnavailable,
-			"already training data")
+			"training data")
 	}
 
 	var docs []entity.Document
 
-	err := c.Bind(&docs)
+	err = c.Bind(&docs)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest,
 			"failed to bind body: "+err.Error())
 	}
 
-	s.waitGroup.Add(1)
-	atomic.StoreInt32(&s.training, 1)
-	go func() {
-		defer s.waitGroup.Done()
-		defer atomic.StoreInt32(&s.training, 0)
-		s.classifier.Train(docs)
-	}()
+	err = entity.ValidateDocuments(docs)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	err = m.Learn(c.Request().Context(), docs)
+	if err != nil {
+		return requestError(c, "failed to learn", err)
+	}
 
-	return c.NoContent(http.StatusAccepted)
+	return c.NoContent(http.StatusNoContent)
 }
 
 func (s *Server) getTraining(c echo.Context) error {
-	return c.JSON(http.StatusOK, atomic.LoadInt32(&s.training) == 1)
+	m, err := s.model(c)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, m.TrainingStatus())
+}
+
+func (s *Server) getStatus(c echo.Context) error {
+	m, err := s.model(c)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, m.Status())
+}
+
+func (s *Server) getVersions(c echo.Context) error {
+	m, err := s.model(c)
+	if err != nil {
+		return err
+	}
+
+	snapshots, err := m.Snapshots()
+	if err != nil {
+		return errors.New("failed to get snapshots: " + err.Error())
+	}
+
+	return c.JSON(http.StatusOK, snapshots)
+}
+
+func (s *Server) postVersionActivate(c echo.Context) error {
+	m, err := s.model(c)
+	if err != nil {
+		return err
+	}
+
+	id := c.Param("id")
+
+	err = s.checkVersion(m, id)
+	if err != nil {
+		return err
+	}
+
+	err = m.ActivateSnapshot(id)
+	if err != nil {
+		return errors.New("failed to activate snapshot: " + err.Error())
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// postVersionEvaluate evaluates snapshot model on documents and saves
+// evaluation to snapshot.
+func (s *Server) postVersionEvaluate(c echo.Context) error {
+	m, err := s.model(c)
+	if err != nil {
+		return err
+	}
+
+	var docs []entity.Document
+
+	err = c.Bind(&docs)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest,
+			"failed to bind body: "+err.Error())
+	}
+
+	err = entity.ValidateDocuments(docs)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	id := c.Param("id")
+
+	err = s.checkVersion(m, id)
+	if err != nil {
+		return err
+	}
+
+	e, err := m.EvaluateSnapshot(c.Request().Context(), id, docs)
+	if err != nil {
+		return requestError(c, "failed to evaluate snapshot", err)
+	}
+
+	return c.JSON(http.StatusOK, e)
+}
+
+// checkVersion returns not found error if model has no snapshot with id.
+func (s *Server) checkVersion(m Classifier, id string) error {
+	snapshots, err := m.Snapshots()
+	if err != nil {
+		return errors.New("failed to get snapshots: " + err.Error())
+	}
+
+	for _, snapshot := range snapshots {
+		if snapshot.ID == id {
+			return nil
+		}
+	}
+
+	return echo.NewHTTPError(http.StatusNotFound, "version not found")
+}
+
+func (s *Server) getConfig(c echo.Context) error {
+	m, err := s.model(c)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, m.Config())
+}
+
+func (s *Server) putConfig(c echo.Context) error {
+	m, err := s.model(c)
+	if err != nil {
+		return err
+	}
+
+	var cfg entity.ModelConfig
+
+	err = c.Bind(&cfg)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest,
+			"failed to bind body: "+err.Error())
+	}
+
+	err = m.SetConfig(cfg)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest,
+			"failed to set config: "+err.Error())
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (s *Server) getStopWords(c echo.Context) error {
+	m, err := s.model(c)
+	if err != nil {
+		return err
+	}
+
+	var b bytes.Buffer
+
+	err = entity.WriteStopWords(&b, m.Config().Extraction.StopWords)
+	if err != nil {
+		return errors.New("failed to write stop words: " + err.Error())
+	}
+
+	return c.Blob(http.StatusOK, echo.MIMETextPlainCharsetUTF8, b.Bytes())
+}
+
+// putStopWords replaces model stop words by list in body. Optional mode
+// query param sets how they are combined with global stop words.
+func (s *Server) putStopWords(c echo.Context) error {
+	m, err := s.model(c)
+	if err != nil {
+		return err
+	}
+
+	sws, err := entity.ParseStopWords(c.Request().Body)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest,
+			"failed to parse stop words: "+err.Error())
+	}
+
+	cfg := m.Config()
+	cfg.Extraction.StopWords = sws
+
+	if mode := c.QueryParam("mode"); mode != "" {
+		cfg.Extraction.StopWordsMode = entity.StopWordsMode(mode)
+	}
+
+	err = m.SetConfig(cfg)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest,
+			"failed to set stop words: "+err.Error())
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (s *Server) getModelExport(c echo.Context) error {
+	m, err := s.model(c)
+	if err != nil {
+		return err
+	}
+
+	e, err := m.Export()
+	if err != nil {
+		return errors.New("failed to export: " + err.Error())
+	}
+
+	return c.JSON(http.StatusOK, e)
+}
+
+func (s *Server) postModelImport(c echo.Context) error {
+	m, err := s.model(c)
+	if err != nil {
+		return err
+	}
+
+	if m.Training() {
+		return echo.NewHTTPError(http.StatusServiceUnavailable,
+			"training data")
+	}
+
+	var e entity.ModelExport
+
+	err = c.Bind(&e)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest,
+			"failed to bind body: "+err.Error())
+	}
+
+	err = m.Import(e)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest,
+			"failed to import: "+err.Error())
+	}
+
+	return c.NoContent(http.StatusNoContent)
 }
 
 func (s *Server) postClassify(c echo.Context) error {
-	if atomic.LoadInt32(&s.training) == 1 {
+	m, err := s.model(c)
+	if err != nil {
+		return err
+	}
+
+	// Labels options enable multi-label mode.
+	var doc struct {
+		Text string
+		entity.LabelsOptions
+	}
+
+	err = c.Bind(&doc)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest,
+			"failed to bind body: "+err.Error())
+	}
+
+	var res entity.Classification
+
+	if doc.LabelsOptions.Enabled() {
+		res, err = m.ClassifyLabels(c.Request().Context(), doc.Text,
+			doc.LabelsOptions)
+	} else {
+		res, err = m.Classify(c.Request().Context(), doc.Text)
+	}
+	if err != nil {
+		return requestError(c, "failed to classify", err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (s *Server) postClassifyBatch(c echo.Context) error {
+	m, err := s.model(c)
+	if err != nil {
+		return err
+	}
+
+	var docs []struct {
+		Text string
+		entity.LabelsOptions
+	}
+
+	err = c.Bind(&docs)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest,
+			"failed to bind body: "+err.Error())
+	}
+
+	texts := make([]string, len(docs))
+	for i, d := range docs {
+		texts[i] = d.Text
+	}
+
+	classifications, errs := m.ClassifyBatch(c.Request().Context(), texts)
+
+	type result struct {
+		Classification *entity.Classification
+		Error          string
+	}
+
+	results := make([]result, len(docs))
+	for i := range results {
+		if errs[i] != nil {
+			results[i].Error = "failed to classify: " + errs[i].Error()
+			continue
+		}
+		if docs[i].LabelsOptions.Enabled() {
+			classifications[i].Labels =
+				classifications[i].SelectLabels(docs[i].LabelsOptions)
+		}
+		results[i].Classification = &classifications[i]
+	}
+
+	return c.JSON(http.StatusOK, results)
+}
+
+func (s *Server) postFeedback(c echo.Context) error {
+	m, err := s.model(c)
+	if err != nil {
+		return err
+	}
+
+	var feedback struct {
+		entity.Feedback
+		Learn bool
+	}
+
+	err = c.Bind(&feedback)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest,
+			"failed to bind body: "+err.Error())
+	}
+
+	if feedback.Correct == "" {
+		return echo.NewHTTPError(http.StatusBadRequest,
+			"correct class is not specified")
+	}
+
+	if feedback.Learn && m.Training() {
 		return echo.NewHTTPError(http.StatusServiceUnavailable,
 			"training data")
 	}
 
+	err = m.RecordFeedback(c.Request().Context(), feedback.Feedback,
+		feedback.Learn)
+	if err != nil {
+		return requestError(c, "failed to record feedback", err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// defaultExplainTop is a default number of words explained per class.
+const defaultExplainTop = 10
+
+func (s *Server) postExplain(c echo.Context) error {
+	m, err := s.model(c)
+	if err != nil {
+		return err
+	}
+
 	var doc struct {
 		Text string
+		Top  int
 	}
 
-	err := c.Bind(&doc)
+	err = c.Bind(&doc)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest,
 			"failed to bind body: "+err.Error())
 	}
 
-	class, err := s.classifier.Classify(doc.Text)
+	if doc.Top == 0 {
+		doc.Top = defaultExplainTop
+	}
+
+	exp, err := m.Explain(c.Request().Context(), doc.Text, doc.Top)
 	if err != nil {
-		return errors.New("failed to classify: " + err.Error())
+		return requestError(c, "failed to explain", err)
+	}
+
+	return c.JSON(http.StatusOK, exp)
+}
+
+// postEvaluate cross-validates model configuration on documents. Served
+// model isn't changed.
+func (s *Server) postEvaluate(c echo.Context) error {
+	m, err := s.model(c)
+	if err != nil {
+		return err
+	}
+
+	var body struct {
+		Documents []entity.Document
+		entity.EvaluationOptions
+	}
+
+	err = c.Bind(&body)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest,
+			"failed to bind body: "+err.Error())
+	}
+
+	err = entity.ValidateDocuments(body.Documents)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	err = entity.ValidateEvaluationOptions(body.EvaluationOptions,
+		len(body.Documents))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	e, err := m.CrossValidate(c.Request().Context(), body.Documents,
+		body.EvaluationOptions)
+	if err != nil {
+		return requestError(c, "failed to evaluate", err)
+	}
+
+	return c.JSON(http.StatusOK, e)
+}
+
+// requestError returns gateway timeout error if request deadline is
+// exceeded and internal error otherwise.
+func requestError(c echo.Context, msg string, err error) error {
+	if c.Request().Context().Err() == context.DeadlineExceeded {
+		return echo.NewHTTPError(http.StatusGatewayTimeout,
+			msg+": "+err.Error())
+	}
+	return errors.New(msg + ": " + err.Error())
+}
+
+// model returns model named by request path or default model.
+func (s *Server) model(c echo.Context) (Classifier, error) {
+	name := c.Param("name")
+	if name == "" {
+		name = s.defaultModel
+	}
+
+	m, exists := s.models.Model(name)
+	if !exists {
+		return nil, echo.NewHTTPError(http.StatusNotFound, "model not found")
+	}
+
+	return m, nil
+}
+
+func (s *Server) getCache(c echo.Context) error {
+	stats, enabled := s.models.CacheStats()
+	if !enabled {
+		return echo.NewHTTPError(http.StatusNotFound, "cache is disabled")
+	}
+
+	return c.JSON(http.StatusOK, stats)
+}
+
+func (s *Server) getModels(c echo.Context) error {
+	return c.JSON(http.StatusOK, s.models.ModelNames())
+}
+
+func (s *Server) postModels(c echo.Context) error {
+	var model struct {
+		Name string
+	}
+
+	err := c.Bind(&model)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest,
+			"failed to bind body: "+err.Error())
+	}
+
+	if _, exists := s.models.Model(model.Name); exists {
+		return echo.NewHTTPError(http.StatusConflict,
+			"model already exists")
+	}
+
+	err = s.models.CreateModel(model.Name)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest,
+			"failed to create model: "+err.Error())
+	}
+
+	return c.NoContent(http.StatusCreated)
+}
+
+func (s *Server) deleteModel(c echo.Context) error {
+	name := c.Param("name")
+
+	if _, exists := s.models.Model(name); !exists {
+		return echo.NewHTTPError(http.StatusNotFound, "model not found")
+	}
+
+	err := s.models.DeleteModel(name)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest,
+			"failed to delete model: "+err.Error())
 	}
 
-	return c.JSON(http.StatusOK, class)
+	return c.NoContent(http.StatusNoContent)
 }
@@ -16,31 +16,72 @@ import (
 )
 
 type Classifier interface {
-	Train(docs []entity.Document)
+	StartTraining(docs []entity.Document) entity.TrainingJob
+	TrainingJob(id string) (entity.TrainingJob, bool)
+	TrainingJobs() []entity.TrainingJob
+	CancelTrainingJob(id string) bool
+	Training() bool
+	TrainingStatus() entity.TrainingStatus
+	Status() entity.ModelStatus
+	Snapshots() ([]entity.Snapshot, error)
+	ActivateSnapshot(id string) error
+	EvaluateSnapshot(ctx context.Context, id string,
+		docs []entity.Document) (entity.Evaluation, error)
+	Export() (entity.ModelExport, error)
+	Config() entity.ModelConfig
+	SetConfig(cfg entity.ModelConfig) error
+	Import(e entity.ModelExport) error
+	Learn(ctx context.Context, docs []entity.Document) error
 	Trained() bool
-	Classify(doc string) (string, error)
+	Classify(ctx context.Context, doc string) (entity.Classification, error)
+	ClassifyLabels(ctx context.Context, doc string,
+		o entity.LabelsOptions) (entity.Classification, error)
+	ClassifyBatch(ctx context.Context, docs []string) (
+		[]entity.Classification, []error)
+	Explain(ctx context.Context, doc string, top int) (entity.Explanation,
+		error)
+	RecordFeedback(ctx context.Context, f entity.Feedback, learn bool) error
+	CrossValidate(ctx context.Context, docs []entity.Document,
+		o entity.EvaluationOptions) (entity.Evaluation, error)
+}
+
+type Models interface {
+	Model(name string) (Classifier, bool)
+	CreateModel(name string) error
+	DeleteModel(name string) error
+	ModelNames() []string
+
+	// CacheStats returns words extraction cache statistics. It returns
+	// false if cache is disabled.
+	CacheStats() (entity.CacheStats, bool)
 }
 
 type Server struct {
-	bindAddr   string
-	debug      bool
-	classifier Classifier
+	bindAddr       string
+	debug          bool
+	models         Models
+	defaultModel   string
+	requestTimeout time.Duration
 
 	echo *echo.Echo
 
-	training int32
-
 	waitGroup sync.WaitGroup
 
 	log *logrus.Entry
 }
 
-func NewServer(bindAddr string, c Classifier, debug bool) *Server {
+// NewServer creates server. Routes without model name are served by
+// defaultModel. Requests are canceled after requestTimeout if it's
+// positive.
+func NewServer(bindAddr string, ms Models, defaultModel string,
+	debug bool, requestTimeout time.Duration) *Server {
 
 	return &Server{
-		bindAddr:   bindAddr,
-		debug:      debug,
-		classifier: c,
+		bindAddr:       bindAddr,
+		debug:          debug,
+		models:         ms,
+		defaultModel:   defaultModel,
+		requestTimeout: requestTimeout,
 
 		log: logrus.WithField("subsystem", "web_server"),
 	}
@@ -57,6 +98,10 @@ func (s *Server) Start() {
 	e.Use(middleware.Recover())
 	e.Use(logrusLogger)
 
+	if s.requestTimeout > 0 {
+		e.Use(timeout(s.requestTimeout))
+	}
+
 	e.HTTPErrorHandler = func(err error, c echo.Context) {
 		var (
 			code = http.StatusInternalServerError
@@ -88,9 +133,23 @@ func (s *Server) Start() {
 		}
 	}
 
-	e.POST("/train", s.postTrain)
-	e.POST("/classify", s.postClassify)
-	e.GET("/training", s.getTraining)
+	e.GET("/models", s.getModels)
+	e.POST("/models", s.postModels)
+
+	e.GET("/cache", s.getCache)
+
+	// Versions of default model.
+	e.GET("/models/versions", s.getVersions)
+	e.POST("/models/versions/:id/activate", s.postVersionActivate)
+	e.POST("/models/versions/:id/evaluate", s.postVersionEvaluate)
+
+	s.addModelRoutes(e.Group(""))
+
+	// Group registers not found handler for its prefix, so model deletion
+	// is added to the group after it's created.
+	g := e.Group("/models/:name")
+	g.DELETE("", s.deleteModel)
+	s.addModelRoutes(g)
 
 	s.echo = e
 
@@ -104,6 +163,30 @@ func (s *Server) Start() {
 	}()
 }
 
+func (s *Server) addModelRoutes(g *echo.Group) {
+	g.POST("/train", s.postTrain)
+	g.GET("/train/jobs", s.getTrainJobs)
+	g.GET("/train/jobs/:id", s.getTrainJob)
+	g.DELETE("/train/jobs/:id", s.deleteTrainJob)
+	g.POST("/learn", s.postLearn)
+	g.POST("/classify", s.postClassify)
+	g.POST("/classify/batch", s.postClassifyBatch)
+	g.GET("/training", s.getTraining)
+	g.GET("/status", s.getStatus)
+	g.GET("/versions", s.getVersions)
+	g.POST("/versions/:id/activate", s.postVersionActivate)
+	g.POST("/versions/:id/evaluate", s.postVersionEvaluate)
+	g.GET("/config", s.getConfig)
+	g.PUT("/config", s.putConfig)
+	g.GET("/stopwords", s.getStopWords)
+	g.PUT("/stopwords", s.putStopWords)
+	g.GET("/model/export", s.getModelExport)
+	g.POST("/model/import", s.postModelImport)
+	g.POST("/feedback", s.postFeedback)
+	g.POST("/explain", s.postExplain)
+	g.POST("/evaluate", s.postEvaluate)
+}
+
 func (s *Server) Stop() {
 	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Second)
 	defer cancel()
@@ -116,6 +199,21 @@ func (s *Server) Stop() {
 	s.waitGroup.Wait()
 }
 
+// timeout sets request context deadline. Handlers pass request context to
+// models, so words extraction is stopped after deadline.
+func timeout(d time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx, cancel := context.WithTimeout(c.Request().Context(), d)
+			defer cancel()
+
+			c.SetRequest(c.Request().WithContext(ctx))
+
+			return next(c)
+		}
+	}
+}
+
 func logrusLogger(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		start := time.Now()
@@ -0,0 +1,221 @@
+package web_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dimuls/classifier"
+	"github.com/dimuls/classifier/entity"
+	"github.com/dimuls/classifier/web"
+)
+
+// fieldsWordsExtractor extracts space separated words.
+type fieldsWordsExtractor struct{}
+
+func (fieldsWordsExtractor) ExtractWords(text string) ([]string, error) {
+	return strings.Fields(text), nil
+}
+
+// registryModels adapts registry to web server.
+type registryModels struct {
+	registry *classifier.Registry
+}
+
+func (rm registryModels) Model(name string) (web.Classifier, bool) {
+	m, err := rm.registry.Model(name)
+	if err != nil {
+		return nil, false
+	}
+	return m, true
+}
+
+func (rm registryModels) CreateModel(name string) error {
+	_, err := rm.registry.Create(name)
+	return err
+}
+
+func (rm registryModels) DeleteModel(name string) error {
+	return rm.registry.Delete(name)
+}
+
+func (rm registryModels) ModelNames() []string {
+	return rm.registry.Names()
+}
+
+func (rm registryModels) CacheStats() (entity.CacheStats, bool) {
+	return entity.CacheStats{}, false
+}
+
+// startServer starts server of registry models on free port and returns
+// its URL.
+func startServer(t *testing.T, r *classifier.Registry) (string, func()) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	s := web.NewServer(addr, registryModels{registry: r},
+		classifier.DefaultModelName, false, 0)
+	s.Start()
+
+	url := "http://" + addr
+
+	for i := 0; ; i++ {
+		res, err := http.Get(url + "/models")
+		if err == nil {
+			res.Body.Close()
+			break
+		}
+		if i == 100 {
+			s.Stop()
+			t.Fatal("server isn't started: " + err.Error())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return url, s.Stop
+}
+
+func request(t *testing.T, method string, url string, body interface{},
+	wantCode int) {
+
+	t.Helper()
+
+	var b bytes.Buffer
+
+	if body != nil {
+		err := json.NewEncoder(&b).Encode(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	req, err := http.NewRequest(method, url, &b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != wantCode {
+		msg, _ := ioutil.ReadAll(res.Body)
+		t.Fatalf("%s %s got status %d, want %d: %s", method, url,
+			res.StatusCode, wantCode, msg)
+	}
+}
+
+// newRegistry creates registry of models persisted to temporary directory.
+func newRegistry(t *testing.T) (*classifier.Registry, string, func()) {
+	dir, err := ioutil.TempDir("", "classifier")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := classifier.NewRegistry(classifier.RegistryConfig{
+		DataDir:       dir,
+		KeepSnapshots: 2,
+	}, classifier.StaticWordsExtractorFactory(fieldsWordsExtractor{}))
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+
+	return r, dir, func() { os.RemoveAll(dir) }
+}
+
+func TestDeleteModel(t *testing.T) {
+	r, dir, remove := newRegistry(t)
+	defer remove()
+
+	url, stop := startServer(t, r)
+	defer stop()
+
+	modelURL := url + "/models/news"
+
+	request(t, http.MethodPost, url+"/models",
+		map[string]string{"Name": "news"}, http.StatusCreated)
+	request(t, http.MethodPut, modelURL+"/config",
+		entity.ModelConfig{MinProbability: 0.5}, http.StatusNoContent)
+	request(t, http.MethodPost, modelURL+"/train", []entity.Document{
+		{Class: "politics", Text: "election vote party"},
+		{Class: "sport", Text: "football match goal"},
+	}, http.StatusAccepted)
+
+	m, err := r.Model("news")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Wait()
+
+	for _, suffix := range []string{"", ".meta", ".config", ".snapshots"} {
+		_, err := os.Stat(filepath.Join(dir, "news.model"+suffix))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	request(t, http.MethodDelete, modelURL, nil, http.StatusNoContent)
+
+	files, err := filepath.Glob(filepath.Join(dir, "news.model*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 0 {
+		t.Errorf("model files %q aren't removed", files)
+	}
+
+	if _, err := r.Model("news"); err != classifier.ErrModelNotFound {
+		t.Errorf("got error %v, want %v", err, classifier.ErrModelNotFound)
+	}
+
+	request(t, http.MethodDelete, modelURL, nil, http.StatusNotFound)
+}
+
+func TestEvaluateInvalidOptions(t *testing.T) {
+	r, _, remove := newRegistry(t)
+	defer remove()
+
+	url, stop := startServer(t, r)
+	defer stop()
+
+	docs := []entity.Document{
+		{Class: "politics", Text: "election vote party"},
+		{Class: "sport", Text: "football match goal"},
+		{Class: "politics", Text: "parliament vote law"},
+		{Class: "sport", Text: "hockey match team"},
+	}
+
+	for _, o := range []entity.EvaluationOptions{
+		{Folds: 1},
+		{Folds: 5},
+		{},
+	} {
+		request(t, http.MethodPost, url+"/evaluate", struct {
+			Documents []entity.Document
+			entity.EvaluationOptions
+		}{Documents: docs, EvaluationOptions: o}, http.StatusBadRequest)
+	}
+
+	request(t, http.MethodPost, url+"/evaluate", struct {
+		Documents []entity.Document
+		entity.EvaluationOptions
+	}{
+		Documents:         docs,
+		EvaluationOptions: entity.EvaluationOptions{Folds: 2},
+	}, http.StatusOK)
+}